@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cagrigit-hub/golosus/codegen"
+	"github.com/cagrigit-hub/golosus/scaffold"
+)
+
+var addCommand = &command{
+	name:    "add",
+	args:    "<feature>",
+	summary: "Add a feature of the built-in stack to an existing project.",
+}
+
+func init() {
+	addCommand.run = runAdd
+
+	if m, err := scaffold.LoadManifest(scaffold.Templates()); err == nil {
+		var lines []string
+		for _, name := range codegen.Features(m) {
+			lines = append(lines, fmt.Sprintf("  %-10s %s", name, m.Features[name].Description))
+		}
+		addCommand.summary += "\n\nfeatures:\n" + strings.Join(lines, "\n")
+	}
+}
+
+func runAdd(ctx context.Context, args []string) error {
+	fs := addCommand.flags()
+	out := outputFlags(fs)
+	feature, err := parseName(fs, args)
+	if err != nil {
+		return err
+	}
+	return out.write(ctx, func(w *codegen.Workspace) (*scaffold.Project, error) {
+		return w.Feature(feature)
+	})
+}
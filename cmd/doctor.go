@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cagrigit-hub/golosus/codegen"
+	"github.com/cagrigit-hub/golosus/doctor"
+	"github.com/cagrigit-hub/golosus/scaffold"
+)
+
+var doctorCommand = &command{
+	name:    "doctor",
+	summary: "Check that the tools a generated project needs are installed in the right versions.",
+}
+
+func init() {
+	doctorCommand.run = runDoctor
+}
+
+func runDoctor(ctx context.Context, args []string) error {
+	fs := doctorCommand.flags()
+	dir := fs.String("dir", ".", "project to check; outside a project the built-in stack is checked")
+	asJSON := fs.Bool("json", false, "print a machine-readable JSON report")
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+
+	root, gomod, packageJSON, err := projectManifests(*dir)
+	if err != nil {
+		return err
+	}
+	reqs, err := doctor.Requirements(gomod, packageJSON)
+	if err != nil {
+		return err
+	}
+	for i := range reqs {
+		if reqs[i].Dir != "" {
+			reqs[i].Dir = filepath.Join(root, reqs[i].Dir)
+		}
+	}
+	report := doctor.Run(ctx, reqs)
+
+	if *asJSON {
+		out, err := report.JSON()
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+	} else {
+		for _, c := range report.Checks {
+			line := fmt.Sprintf("%-8s %-6s", c.Status, c.Tool)
+			if c.Version != "" {
+				line += " " + c.Version
+			}
+			if c.Minimum != "" {
+				want := ">= " + c.Minimum
+				if c.Exact {
+					want = "= " + c.Minimum
+				}
+				line += " (needs " + want + ")"
+			}
+			if c.Optional && c.Status != doctor.StatusOK {
+				line += " [optional]"
+			}
+			fmt.Println(line)
+			if c.Fix != "" {
+				fmt.Printf("         fix: %s\n", c.Fix)
+			}
+		}
+	}
+	if !report.OK {
+		return errors.New("some required tools are missing or outdated")
+	}
+	return nil
+}
+
+// projectManifests returns the root, go.mod and typescript/package.json of
+// the project in dir or, outside a project, dir and the files of the
+// built-in stack.
+func projectManifests(dir string) (root string, gomod, packageJSON []byte, err error) {
+	if w, err := codegen.Open(dir); err == nil {
+		gomod, err = os.ReadFile(filepath.Join(w.Root, "go.mod"))
+		if err != nil {
+			return "", nil, nil, err
+		}
+		packageJSON, err = os.ReadFile(filepath.Join(w.Root, "typescript", "package.json"))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", nil, nil, err
+		}
+		return w.Root, gomod, packageJSON, nil
+	}
+
+	p, err := scaffold.New(scaffold.Data{Name: "doctor", Module: "example.com/doctor"})
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if content, ok := p.File("go.mod"); ok {
+		gomod = []byte(content)
+	}
+	if content, ok := p.File("typescript/package.json"); ok {
+		packageJSON = []byte(content)
+	}
+	return dir, gomod, packageJSON, nil
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cagrigit-hub/golosus/codegen"
+	"github.com/cagrigit-hub/golosus/scaffold"
+)
+
+var generateCommand = &command{
+	name:    "generate",
+	args:    "handler|component|model|resource|page [flags] <Name>",
+	summary: "Add a handler, component, model, resource or page to the project in the current directory.",
+}
+
+var (
+	generateHandler = &command{
+		name:    "generate handler",
+		args:    "<Name>",
+		summary: "Create handler/<name>.go with its view and model and register its routes in cmd/main.go.",
+	}
+	generateComponent = &command{
+		name:    "generate component",
+		args:    "<Name>",
+		summary: "Create view/components/<name>.templ with a <Name>Props struct and a test rendering it.",
+	}
+	generateModel = &command{
+		name:    "generate model",
+		args:    "<Name> [field:type[:rules]...]",
+		summary: "Create model/<name>.go with struct tags, a Validate method and a fixture helper.\n\nfield types: " + strings.Join(codegen.FieldTypes, ", ") + "\nrules, comma separated: " + strings.Join(codegen.Rules, ", ") + "\ne.g. golosus generate model Post title:string:required,max=100 views:int:min=0",
+	}
+	generateResource = &command{
+		name:    "generate resource",
+		args:    "<Name> [field:type[:rules]...]",
+		summary: "Create a model, repository, CRUD handler and htmx views and register the routes in cmd/main.go.\n\nFields are declared as for generate model.\ne.g. golosus generate resource Post title:string:required published:bool",
+	}
+	generatePage = &command{
+		name:    "generate page",
+		args:    "<path>",
+		summary: "Create a page at a URL path such as about or admin/users: a view package under view/, a handler method, a GET route and a navigation link.",
+	}
+)
+
+// generators are the kinds of generate, named "generate <kind>".
+var generators = []*command{
+	generateHandler,
+	generateComponent,
+	generateModel,
+	generateResource,
+	generatePage,
+}
+
+func init() {
+	generateCommand.run = runGenerate
+	generateHandler.run = runGenerateHandler
+	generateComponent.run = runGenerateComponent
+	generateModel.run = runGenerateModel
+	generateResource.run = runGenerateResource
+	generatePage.run = runGeneratePage
+}
+
+func runGenerate(ctx context.Context, args []string) error {
+	if len(args) > 0 {
+		for _, gen := range generators {
+			if gen.name == "generate "+args[0] {
+				return gen.run(ctx, args[1:])
+			}
+		}
+	}
+	fmt.Fprintf(os.Stderr, "usage: golosus %s %s\n\n%s\n\nkinds:\n", generateCommand.name, generateCommand.args, generateCommand.summary)
+	for _, gen := range generators {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", gen.name[len("generate "):], gen.summary)
+	}
+	if len(args) == 0 {
+		return errors.New("generate: missing kind")
+	}
+	switch args[0] {
+	case "-h", "-help", "--help", "help":
+		return errHelp
+	}
+	return fmt.Errorf("generate: unknown kind %q", args[0])
+}
+
+func runGenerateHandler(ctx context.Context, args []string) error {
+	fs := generateHandler.flags()
+	out := outputFlags(fs)
+	routesFlag := fs.String("routes", "", "comma separated METHOD:/path routes, e.g. GET:/users,POST:/users (default GET and POST on /<name>)")
+	name, err := parseName(fs, args)
+	if err != nil {
+		return err
+	}
+	var routes []codegen.Route
+	if *routesFlag != "" {
+		if routes, err = codegen.ParseRoutes(*routesFlag); err != nil {
+			return err
+		}
+	}
+	return out.write(ctx, func(w *codegen.Workspace) (*scaffold.Project, error) {
+		return w.Handler(name, routes)
+	})
+}
+
+func runGenerateComponent(ctx context.Context, args []string) error {
+	fs := generateComponent.flags()
+	out := outputFlags(fs)
+	propsFlag := fs.String("props", "", "comma separated Name:type[=default] props, e.g. Label:string,Disabled:bool; types: "+strings.Join(codegen.PropTypes, ", "))
+	name, err := parseName(fs, args)
+	if err != nil {
+		return err
+	}
+	props, err := codegen.ParseProps(*propsFlag)
+	if err != nil {
+		return err
+	}
+	return out.write(ctx, func(w *codegen.Workspace) (*scaffold.Project, error) {
+		return w.Component(name, props)
+	})
+}
+
+func runGenerateModel(ctx context.Context, args []string) error {
+	fs := generateModel.flags()
+	out := outputFlags(fs)
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		fs.Usage()
+		return errUsage
+	}
+	fields, err := codegen.ParseFields(positional[1:])
+	if err != nil {
+		return err
+	}
+	return out.write(ctx, func(w *codegen.Workspace) (*scaffold.Project, error) {
+		return w.Model(positional[0], fields)
+	})
+}
+
+func runGenerateResource(ctx context.Context, args []string) error {
+	fs := generateResource.flags()
+	out := outputFlags(fs)
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		fs.Usage()
+		return errUsage
+	}
+	fields, err := codegen.ParseFields(positional[1:])
+	if err != nil {
+		return err
+	}
+	return out.write(ctx, func(w *codegen.Workspace) (*scaffold.Project, error) {
+		return w.Resource(positional[0], fields)
+	})
+}
+
+func runGeneratePage(ctx context.Context, args []string) error {
+	fs := generatePage.flags()
+	out := outputFlags(fs)
+	layout := fs.String("layout", "Base", "layout component from view/layout to wrap the page in")
+	path, err := parseName(fs, args)
+	if err != nil {
+		return err
+	}
+	return out.write(ctx, func(w *codegen.Workspace) (*scaffold.Project, error) {
+		return w.Page(path, *layout)
+	})
+}
+
+// parseName parses args and returns the single positional name.
+func parseName(fs *flag.FlagSet, args []string) (string, error) {
+	positional, err := parse(fs, args)
+	if err != nil {
+		return "", err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return "", errUsage
+	}
+	return positional[0], nil
+}
+
+// output holds the flags shared by commands that change an existing project.
+type output struct {
+	dir         *string
+	dryRun      *bool
+	showContent *bool
+}
+
+func outputFlags(fs *flag.FlagSet) *output {
+	return &output{
+		dir:         fs.String("dir", ".", "project directory"),
+		dryRun:      fs.Bool("dry-run", false, "print the planned files without writing anything"),
+		showContent: fs.Bool("show-content", false, "with -dry-run, also print the content of every file"),
+	}
+}
+
+// write plans files for the project in -dir and writes them unless -dry-run
+// is set.
+func (o *output) write(ctx context.Context, plan func(w *codegen.Workspace) (*scaffold.Project, error)) error {
+	w, err := codegen.Open(*o.dir)
+	if err != nil {
+		return err
+	}
+	p, err := plan(w)
+	if err != nil {
+		return err
+	}
+	if *o.dryRun {
+		p.Print(os.Stdout, *o.showContent)
+		return nil
+	}
+	report, err := w.Write(ctx, p)
+	if err != nil {
+		return err
+	}
+	for _, name := range report.Written {
+		if w.Edited(name) {
+			fmt.Fprintf(os.Stderr, "updated   %s\n", name)
+		} else {
+			fmt.Fprintf(os.Stderr, "created   %s\n", name)
+		}
+	}
+	for _, name := range report.Unchanged {
+		fmt.Fprintf(os.Stderr, "unchanged %s\n", name)
+	}
+	return nil
+}
@@ -1,125 +1,119 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 )
 
-type file struct {
+// command is a golosus subcommand. Every command parses its own flags.
+type command struct {
 	name    string
-	content string
+	args    string
+	summary string
+	run     func(ctx context.Context, args []string) error
 }
 
-func main() {
-	// get --name flag
-	var name string
-	flag.StringVar(&name, "name", "Golosus-Web", "project name")
-	var githubProfile string
-	flag.StringVar(&githubProfile, "github", "cagrigit-hub", "github user name")
-	flag.Parse()
+var commands = []*command{
+	newCommand,
+	generateCommand,
+	addCommand,
+	doctorCommand,
+	versionCommand,
+}
 
-	// command := goModInit(name, githubProfile)
-	// exec.Command("sh", "-c", command).Run()
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("golosus: ")
 
-	ct := &Content{}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	folders := []string{
-		"assets",
-		"assets/jscode",
-		"assets/bundled",
-		"cmd",
-		"view",
-		"model",
-		"handler",
-		"view/components",
-		"view/layout",
-		"view/example",
-		"typescript",
-		".",
+	err := run(ctx, os.Args[1:])
+	switch {
+	case err == nil, errors.Is(err, errHelp):
+	case errors.Is(err, errUsage):
+		stop()
+		os.Exit(2)
+	default:
+		stop()
+		log.Fatal(err)
 	}
+}
 
-	for _, folder := range folders {
-		err := createFolders(name + "/" + folder)
-		if err != nil {
-			log.Fatal(err)
-			return
-		}
+func run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		usage()
+		return errors.New("missing command")
 	}
-
-	files := map[string][]file{
-		"cmd": {
-			{"main.go", ct.Main(name, githubProfile)},
-		},
-		"view/layout": {
-			{"base.templ", ct.Layout(name)},
-		},
-		"view/example": {
-			{"example.templ", ct.ExampleView(githubProfile, name)},
-		},
-		"view/components": {
-			{"input.templ", ct.ExampleComponent()},
-		},
-		"handler": {
-			{"util.go", ct.Util()},
-			{"example.go", ct.ExampleHandler(githubProfile, name)},
-		},
-		"model": {
-			{"example.go", ct.ExampleModel()},
-		},
-		".": {
-			{"go.mod", ct.GoMod(githubProfile, name)},
-			{"Makefile", ct.Make()},
-			{".air.toml", ct.Air()},
-		},
-		"typescript": {
-			{"tsconfig.json", ct.TsConfig()},
-			{"index.ts", ct.TypescriptIndex()},
-			{"scripts.ts", ct.ScriptsTs()},
-			{"package.json", ct.PackageJson(name)},
-		},
+	// golosus -name=foo predates the subcommands and still means new.
+	if strings.HasPrefix(args[0], "-") && args[0] != "-h" && args[0] != "-help" && args[0] != "--help" {
+		return newCommand.run(ctx, args)
 	}
-
-	for _, folder := range folders {
-		for _, file := range files[folder] {
-			err := createFiles(name, folder, file.name)
-			if err != nil {
-				log.Fatal(err)
-				return
-			}
-			err = writeFiles(name, folder, file.name, file.content)
-			if err != nil {
-				log.Fatal(err)
-				return
-			}
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(ctx, args[1:])
 		}
-
 	}
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		usage()
+		return nil
+	}
+	usage()
+	return fmt.Errorf("unknown command %q", args[0])
 }
 
-func createFolders(name string) error {
-	if err := os.MkdirAll(name, os.ModePerm); err != nil {
-		return err
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: golosus <command> [flags]\n\ncommands:\n")
+	for _, cmd := range commands {
+		summary, _, _ := strings.Cut(cmd.summary, "\n")
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, summary)
 	}
-	return nil
+	fmt.Fprintf(os.Stderr, "\nrun golosus <command> -help for the flags of a command\n")
 }
 
-func createFiles(rn, target, name string) error {
-	if _, err := os.Create(rn + "/" + target + "/" + name); err != nil {
-		return err
+// flags returns the flag set of cmd with a usage message listing its
+// arguments and summary.
+func (cmd *command) flags() *flag.FlagSet {
+	fs := flag.NewFlagSet("golosus "+cmd.name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: golosus %s [flags] %s\n\n%s\n\nflags:\n", cmd.name, cmd.args, cmd.summary)
+		fs.PrintDefaults()
 	}
-	return nil
+	return fs
 }
 
-func writeFiles(rn, target, name, content string) error {
-	if err := os.WriteFile(rn+"/"+target+"/"+name, []byte(content), os.ModePerm); err != nil {
-		return err
+// parse parses args with fs, also accepting flags after positional
+// arguments, and returns the positional arguments. A -help request is
+// reported as errHelp and a bad flag, already printed by fs, as errUsage.
+func parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, errHelp
+			}
+			return nil, errUsage
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
 	}
-	return nil
 }
 
-func goModInit(name, github string) string {
-	// run command
-	return fmt.Sprintf("go mod init github.com/%s/%s", github, name)
-}
+var (
+	// errHelp stops a command after its usage was printed on request.
+	errHelp = errors.New("help requested")
+	// errUsage stops a command after fs reported a bad flag.
+	errUsage = errors.New("bad usage")
+)
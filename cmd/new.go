@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cagrigit-hub/golosus/scaffold"
+)
+
+var newCommand = &command{
+	name:    "new",
+	args:    "[name]",
+	summary: "Create a new project.",
+}
+
+func init() {
+	newCommand.run = runNew
+}
+
+func runNew(ctx context.Context, args []string) error {
+	fs := newCommand.flags()
+	name := fs.String("name", "", "project name")
+	githubProfile := fs.String("github", "", "github user name")
+	modulePath := fs.String("module", "", "Go module path, e.g. go.corp.example/platform/billing (default github.com/<github>/<name>, or <name> without -github)")
+	templates := fs.String("templates", "", "directory whose templates override or extend the built-in ones")
+	pack := fs.String("template", "", "template pack to generate from: a directory, a tarball or git+<url>[#<ref>]")
+	vars := varsFlag{}
+	fs.Var(vars, "var", "template variable as key=value, may be repeated")
+	backend := fs.String("backend", "", "Go web framework of the built-in stack: echo (default), nethttp, chi, gin or fiber")
+	frontend := fs.String("frontend", "", "client libraries of the built-in stack: htmx-alpine (default), htmx, htmx-hyperscript, datastar or none")
+	assets := fs.String("assets", "", "where the layout loads libraries and styles from: cdn (default) or vendor, bundled into assets/bundled with SRI hashes")
+	css := fs.String("css", "", "how Tailwind styles are loaded: cdn (the Play CDN script) or tailwind, compiled into assets/bundled/styles.css (default with -assets vendor)")
+	bundler := fs.String("bundler", "", "TypeScript bundler: browserify (default) or esbuild, with ES modules, source maps and content-hashed names")
+	answersFile := fs.String("answers", "", "answers file with variable values, as written by -save-answers")
+	saveAnswersTo := fs.String("save-answers", "", "write the resolved variable values to this answers file")
+	noInput := fs.Bool("no-input", false, "never prompt, fail when a required value is missing")
+	skipHooks := fs.Bool("skip-hooks", false, "do not run the post-generation steps (templ generate, go mod tidy, install, git)")
+	allowHooks := fs.Bool("allow-hooks", false, "run the shell commands a -template pack declares as hooks, which are skipped otherwise")
+	goproxy := fs.String("goproxy", "", "GOPROXY for go mod tidy, e.g. off to use the local module cache only")
+	goflags := fs.String("goflags", "", "GOFLAGS for go mod tidy, e.g. -mod=mod")
+	refresh := fs.Bool("refresh", false, "resolve the -template source again instead of using the pinned version")
+	dryRun := fs.Bool("dry-run", false, "print the planned file tree without writing anything")
+	showContent := fs.Bool("show-content", false, "with -dry-run, also print the content of every file")
+	force := fs.Bool("force", false, "overwrite existing files")
+	skipExisting := fs.Bool("skip-existing", false, "keep existing files and only write missing ones")
+	merge := fs.Bool("merge", false, "write only new files and report files that differ as conflicts")
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	switch len(positional) {
+	case 0:
+	case 1:
+		*name = positional[0]
+	default:
+		return fmt.Errorf("new: expected at most one project name, got %q", positional)
+	}
+
+	if *backend != "" {
+		vars["backend"] = *backend
+	}
+	if *frontend != "" {
+		vars["frontend"] = *frontend
+	}
+	if *assets != "" {
+		vars["assets"] = *assets
+	}
+	if *css != "" {
+		vars["css"] = *css
+	}
+	if *bundler != "" {
+		vars["bundler"] = *bundler
+	}
+
+	policy, err := policyFromFlags(*force, *skipExisting, *merge)
+	if err != nil {
+		return err
+	}
+
+	base := scaffold.Templates()
+	if *pack != "" {
+		p, err := scaffold.FetchPack(ctx, *pack, *refresh)
+		if err != nil {
+			return err
+		}
+		base = p.FS()
+	}
+	layers, err := scaffold.TemplateLayers(*templates)
+	if err != nil {
+		return err
+	}
+	m, err := scaffold.LoadManifest(base)
+	if err != nil {
+		return err
+	}
+	data := scaffold.Data{
+		Name:   *name,
+		Module: *modulePath,
+		Author: *githubProfile,
+		Vars:   vars,
+	}
+	if data.Name == "" && data.Module != "" {
+		data.Name = scaffold.ModuleName(data.Module)
+	}
+	if *answersFile != "" {
+		a, err := loadAnswers(*answersFile)
+		if err != nil {
+			return err
+		}
+		a.apply(&data)
+	}
+	if !*noInput && interactive() && missingRequired(m, data) {
+		if data, err = wizard(os.Stdin, os.Stderr, m, data); err != nil {
+			return err
+		}
+	}
+
+	project, err := scaffold.NewFromPack(base, data, layers...)
+	if err != nil {
+		return err
+	}
+	if *saveAnswersTo != "" {
+		if err := saveAnswers(*saveAnswersTo, m, project.Data); err != nil {
+			return err
+		}
+	}
+	if *dryRun {
+		project.Print(os.Stdout, *showContent)
+		return nil
+	}
+
+	entries, _ := os.ReadDir(project.Data.Name)
+	existed := len(entries) > 0
+	report, err := scaffold.Generate(ctx, project, project.Data.Name, policy)
+	if errors.Is(err, scaffold.ErrNotEmpty) {
+		return fmt.Errorf("%w; use -force, -skip-existing or -merge", err)
+	}
+	if err != nil {
+		return err
+	}
+	printReport(report)
+	if backend := project.Data.Vars["backend"]; *pack == "" && backend != "echo" {
+		fmt.Fprintf(os.Stderr, "golosus generate handler, resource and page only support the echo backend, this project uses %s\n", backend)
+	}
+
+	if *skipHooks {
+		return nil
+	}
+	steps, err := m.Steps(project.Data, scaffold.StepOptions{
+		GoMod:    scaffold.GoModOptions{GOPROXY: *goproxy, GOFLAGS: *goflags},
+		Existed:  existed,
+		AllowRun: *pack == "" || *allowHooks,
+	})
+	if err != nil {
+		return err
+	}
+	if *pack != "" && !*allowHooks && runsShell(m) {
+		fmt.Fprintf(os.Stderr, "%s declares shell commands as hooks; review them and pass -allow-hooks to run them\n", *pack)
+	}
+	return runSteps(ctx, project.Data.Name, steps)
+}
+
+// runSteps runs the post-generation steps and reports how each one went. A
+// failed step fails the command only after every step had its turn.
+func runSteps(ctx context.Context, root string, steps []scaffold.Step) error {
+	results := scaffold.RunSteps(ctx, root, steps, os.Stderr)
+	failed := 0
+	fmt.Fprintln(os.Stderr)
+	for _, r := range results {
+		switch {
+		case r.Err == nil:
+			fmt.Fprintf(os.Stderr, "ok   %s (%s)\n", r.Name, r.Duration.Round(time.Millisecond))
+		case errors.Is(r.Err, scaffold.ErrSkipped):
+			fmt.Fprintf(os.Stderr, "skip %s: %v\n", r.Name, r.Err)
+		case errors.Is(r.Err, scaffold.ErrOffline):
+			fmt.Fprintf(os.Stderr, "warn %s: %v\n", r.Name, r.Err)
+		default:
+			failed++
+			fmt.Fprintf(os.Stderr, "FAIL %s: %v\n", r.Name, r.Err)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d post-generation steps failed, the project was generated in %s", failed, len(results), root)
+	}
+	return nil
+}
+
+// runsShell reports whether m declares hooks that run shell commands.
+func runsShell(m *scaffold.Manifest) bool {
+	for _, h := range m.Hooks {
+		if h.Run != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func printReport(report *scaffold.Report) {
+	for _, skipped := range report.Skipped {
+		fmt.Fprintf(os.Stderr, "skipped  %s\n", skipped)
+	}
+	for _, conflict := range report.Conflicts {
+		fmt.Fprintf(os.Stderr, "conflict %s\n", conflict)
+	}
+}
+
+// varsFlag collects repeated -var key=value flags.
+type varsFlag map[string]string
+
+func (v varsFlag) String() string {
+	return ""
+}
+
+func (v varsFlag) Set(s string) error {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("%q is not key=value", s)
+	}
+	v[key] = value
+	return nil
+}
+
+func policyFromFlags(force, skipExisting, merge bool) (scaffold.Policy, error) {
+	policy, set := scaffold.PolicyFail, 0
+	if force {
+		policy, set = scaffold.PolicyForce, set+1
+	}
+	if skipExisting {
+		policy, set = scaffold.PolicySkipExisting, set+1
+	}
+	if merge {
+		policy, set = scaffold.PolicyMerge, set+1
+	}
+	if set > 1 {
+		return policy, errors.New("-force, -skip-existing and -merge are mutually exclusive")
+	}
+	return policy, nil
+}
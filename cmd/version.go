@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// version is set at build time with -ldflags "-X main.version=v1.2.3".
+var version = ""
+
+var versionCommand = &command{
+	name:    "version",
+	summary: "Print the Golosus version.",
+}
+
+func init() {
+	versionCommand.run = runVersion
+}
+
+func runVersion(ctx context.Context, args []string) error {
+	fs := versionCommand.flags()
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+	fmt.Printf("golosus %s %s/%s %s\n", buildVersion(), runtime.GOOS, runtime.GOARCH, runtime.Version())
+	return nil
+}
+
+func buildVersion() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
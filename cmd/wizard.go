@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/cagrigit-hub/golosus/scaffold"
+	"gopkg.in/yaml.v3"
+)
+
+// interactive reports whether stdin is a terminal someone can answer on.
+func interactive() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// missingRequired reports whether a required variable of m has no value or
+// default in data.
+func missingRequired(m *scaffold.Manifest, data scaffold.Data) bool {
+	for _, v := range m.Variables {
+		if v.Required && data.Get(v.Name) == "" && v.Default == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// wizard asks for every variable of m that data does not set yet. Answers are
+// checked as they are given, together with the constraints of m they could
+// break, and asked again until they are valid.
+func wizard(in io.Reader, out io.Writer, m *scaffold.Manifest, data scaffold.Data) (scaffold.Data, error) {
+	r := bufio.NewReader(in)
+	if data.Name != "" {
+		if err := setName(&data, data.Name); err != nil {
+			return data, err
+		}
+	}
+	for _, v := range m.Variables {
+		if data.Get(v.Name) != "" {
+			continue
+		}
+		def, err := v.DefaultFor(data)
+		if err != nil {
+			return data, err
+		}
+		for {
+			fmt.Fprint(out, prompt(v, def))
+			line, err := r.ReadString('\n')
+			if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+				return data, fmt.Errorf("reading %s: %w", v.Name, err)
+			}
+			answer := strings.TrimSpace(line)
+			if answer == "" {
+				answer = def
+			}
+			if err := v.Check(answer); err != nil {
+				fmt.Fprintf(out, "  %v\n", err)
+				continue
+			}
+			next := data
+			next.Vars = maps.Clone(data.Vars)
+			if v.Name == "Name" {
+				if err := setName(&next, answer); err != nil {
+					return data, err
+				}
+			} else {
+				next.Set(v.Name, answer)
+			}
+			broken, err := newlyBroken(m, data, next)
+			if err != nil {
+				return data, err
+			}
+			if len(broken) > 0 {
+				for _, c := range broken {
+					fmt.Fprintf(out, "  %s\n", c.Message)
+				}
+				continue
+			}
+			data = next
+			break
+		}
+	}
+	return data, nil
+}
+
+// newlyBroken returns the constraints of m that next breaks but data, the
+// answers before the last one, did not. Constraints on variables not asked
+// for yet are left to the answers that follow.
+func newlyBroken(m *scaffold.Manifest, data, next scaffold.Data) ([]scaffold.Constraint, error) {
+	before, err := m.Broken(data)
+	if err != nil {
+		return nil, err
+	}
+	after, err := m.Broken(next)
+	if err != nil {
+		return nil, err
+	}
+	var broken []scaffold.Constraint
+	for _, c := range after {
+		if !slices.Contains(before, c) {
+			broken = append(broken, c)
+		}
+	}
+	return broken, nil
+}
+
+// setName sets the project name and the spellings derived from it, so the
+// defaults asked for later, like the module path, use the normalized name.
+func setName(data *scaffold.Data, name string) error {
+	names, err := scaffold.NormalizeName(name)
+	if err != nil {
+		return err
+	}
+	data.Name = names.Dir
+	if data.Title == "" {
+		data.Title = names.Title
+	}
+	if data.NPMName == "" {
+		data.NPMName = names.NPM
+	}
+	return nil
+}
+
+func prompt(v scaffold.Variable, def string) string {
+	var b strings.Builder
+	b.WriteString(v.Name)
+	if v.Description != "" {
+		fmt.Fprintf(&b, " (%s)", v.Description)
+	}
+	if len(v.Choices) > 0 {
+		fmt.Fprintf(&b, " [%s]", strings.Join(v.Choices, "/"))
+	}
+	if def != "" {
+		fmt.Fprintf(&b, " {%s}", def)
+	}
+	b.WriteString(": ")
+	return b.String()
+}
+
+// answers are the values of the variables of a manifest, as stored in an
+// answers file.
+type answers map[string]string
+
+func loadAnswers(name string) (answers, error) {
+	src, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	a := answers{}
+	if err := yaml.Unmarshal(src, &a); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return a, nil
+}
+
+// apply sets every answer that data does not set yet.
+func (a answers) apply(data *scaffold.Data) {
+	for name, value := range a {
+		if data.Get(name) == "" {
+			data.Set(name, value)
+		}
+	}
+}
+
+func saveAnswers(name string, m *scaffold.Manifest, data scaffold.Data) error {
+	src, err := yaml.Marshal(m.Answers(data))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, src, 0o644)
+}
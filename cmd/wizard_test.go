@@ -0,0 +1,55 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/cagrigit-hub/golosus/scaffold"
+)
+
+func TestWizardNormalizesName(t *testing.T) {
+	m, err := scaffold.LoadManifest(scaffold.Templates())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The name, then Enter for every other variable.
+	in := strings.NewReader("My App2\n" + strings.Repeat("\n", len(m.Variables)))
+	data, err := wizard(in, io.Discard, m, scaffold.Data{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.Name != "my-app2" || data.Title != "My App2" || data.NPMName != "my-app2" {
+		t.Errorf("got Name %q, Title %q, NPMName %q", data.Name, data.Title, data.NPMName)
+	}
+	if data.Module != "my-app2" {
+		t.Errorf("Module = %q, want the default derived from the normalized name", data.Module)
+	}
+}
+
+func TestWizardConstraints(t *testing.T) {
+	m, err := scaffold.LoadManifest(scaffold.Templates())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// assets vendor and css cdn break a constraint, so css is asked again.
+	answers := map[string]string{"Name": "app", "assets": "vendor", "css": "cdn\ntailwind"}
+	var in strings.Builder
+	for _, v := range m.Variables {
+		in.WriteString(answers[v.Name] + "\n")
+	}
+	var out strings.Builder
+	data, err := wizard(strings.NewReader(in.String()), &out, m, scaffold.Data{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(out.String(), "css ("); got != 2 {
+		t.Errorf("css was asked %d times, want 2:\n%s", got, out.String())
+	}
+	if !strings.Contains(out.String(), "assets vendor loads nothing from third-party origins") {
+		t.Errorf("the broken constraint was not explained:\n%s", out.String())
+	}
+	if data.Vars["assets"] != "vendor" || data.Vars["css"] != "tailwind" {
+		t.Errorf("got assets %q, css %q", data.Vars["assets"], data.Vars["css"])
+	}
+}
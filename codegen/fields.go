@@ -0,0 +1,392 @@
+package codegen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/mail"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldTypes are the Go types model fields can have.
+var FieldTypes = []string{"string", "int", "int64", "float64", "bool"}
+
+// Rules are the validation rules model fields can declare.
+var Rules = []string{"required", "min=N", "max=N", "email", "oneof=a|b"}
+
+// Field is a field of a generated model.
+type Field struct {
+	Names
+	Type string
+
+	Required bool
+	Email    bool
+	// Min and Max bound the length of strings and the value of numbers.
+	Min, Max *float64
+	OneOf    []string
+}
+
+// ParseFields parses name:type[:rules] field specs such as
+// "title:string:required,max=100" or "views:int:min=0".
+func ParseFields(specs []string) (Fields, error) {
+	var fields Fields
+	seen := map[string]bool{}
+	for _, spec := range specs {
+		parts := strings.SplitN(spec, ":", 3)
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("field %q: want name:type[:rules]", spec)
+		}
+		n, err := names(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", spec, err)
+		}
+		if seen[n.Name] {
+			return nil, fmt.Errorf("field %s: listed twice", n.Name)
+		}
+		seen[n.Name] = true
+		f := Field{Names: n, Type: parts[1]}
+		if !slices.Contains(FieldTypes, f.Type) {
+			return nil, fmt.Errorf("field %s: unsupported type %q, use one of %s", n.Name, f.Type, strings.Join(FieldTypes, ", "))
+		}
+		if len(parts) == 3 {
+			if err := f.parseRules(parts[2]); err != nil {
+				return nil, fmt.Errorf("field %s: %w", n.Name, err)
+			}
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+func (f *Field) parseRules(rules string) error {
+	for _, rule := range strings.Split(rules, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch key {
+		case "":
+		case "required":
+			f.Required = true
+		case "email":
+			if f.Type != "string" {
+				return fmt.Errorf("email needs a string field")
+			}
+			f.Email = true
+		case "min", "max":
+			if f.Type == "bool" {
+				return fmt.Errorf("%s needs a string or number field", key)
+			}
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil || (f.Type != "float64" && n != float64(int64(n))) {
+				return fmt.Errorf("%s=%s: not a valid bound", key, value)
+			}
+			if key == "min" {
+				f.Min = &n
+			} else {
+				f.Max = &n
+			}
+		case "oneof":
+			if f.Type == "bool" || value == "" {
+				return fmt.Errorf("oneof needs a string or number field and values")
+			}
+			f.OneOf = strings.Split(value, "|")
+			for _, v := range f.OneOf {
+				if _, err := f.literal(v); err != nil {
+					return fmt.Errorf("oneof: %w", err)
+				}
+			}
+		default:
+			return fmt.Errorf("unknown rule %q, use %s", rule, strings.Join(Rules, ", "))
+		}
+	}
+	if f.Min != nil && f.Max != nil && *f.Min > *f.Max {
+		return fmt.Errorf("min is greater than max")
+	}
+	if _, ok := f.fixture(); !ok {
+		return fmt.Errorf("no %s value passes %s", f.Type, rules)
+	}
+	return nil
+}
+
+// literal returns v as a Go literal of the type of f.
+func (f *Field) literal(v string) (string, error) {
+	switch f.Type {
+	case "string":
+		return strconv.Quote(v), nil
+	case "int", "int64":
+		if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+			return "", fmt.Errorf("%q is not an integer", v)
+		}
+	case "float64":
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			return "", fmt.Errorf("%q is not a number", v)
+		}
+	}
+	return v, nil
+}
+
+func (f *Field) bound(n *float64) string {
+	return strconv.FormatFloat(*n, 'f', -1, 64)
+}
+
+// Check is a validation rule of a field rendered as Go.
+type Check struct {
+	// Init is an optional statement run before Broken is evaluated.
+	Init string
+	// Broken is a boolean Go expression that holds when the rule is broken.
+	Broken  string
+	Message string
+}
+
+// Checks returns the rules of f as Go checks of the field of receiver recv.
+func (f Field) Checks(recv string) []Check {
+	x := recv + "." + f.Name
+	length := x
+	unit := ""
+	if f.Type == "string" {
+		length, unit = "utf8.RuneCountInString("+x+")", " characters"
+	}
+	var checks []Check
+	if f.Required {
+		zero := map[string]string{"string": `""`, "bool": "false"}[f.Type]
+		if zero == "" {
+			zero = "0"
+		}
+		checks = append(checks, Check{Broken: x + " == " + zero, Message: f.File + " is required"})
+	}
+	if f.Min != nil {
+		msg := fmt.Sprintf("%s must be at least %s%s", f.File, f.bound(f.Min), unit)
+		checks = append(checks, Check{Broken: length + " < " + f.bound(f.Min), Message: msg})
+	}
+	if f.Max != nil {
+		msg := fmt.Sprintf("%s must be at most %s%s", f.File, f.bound(f.Max), unit)
+		checks = append(checks, Check{Broken: length + " > " + f.bound(f.Max), Message: msg})
+	}
+	if f.Email {
+		checks = append(checks, Check{
+			Init:    "_, err := mail.ParseAddress(" + x + ")",
+			Broken:  x + ` != "" && err != nil`,
+			Message: f.File + " must be an email address",
+		})
+	}
+	if len(f.OneOf) > 0 {
+		var values []string
+		for _, v := range f.OneOf {
+			lit, _ := f.literal(v)
+			values = append(values, lit)
+		}
+		msg := fmt.Sprintf("%s must be one of %s", f.File, strings.Join(f.OneOf, ", "))
+		checks = append(checks, Check{
+			Broken:  fmt.Sprintf("!slices.Contains([]%s{%s}, %s)", f.Type, strings.Join(values, ", "), x),
+			Message: msg,
+		})
+	}
+	return checks
+}
+
+// Fixture returns a Go literal for a value of f that passes its rules.
+// ParseFields rejects rules no value passes.
+func (f Field) Fixture() string {
+	v, _ := f.fixture()
+	lit, _ := f.literal(v)
+	return lit
+}
+
+// fixture returns the first candidate value of f that passes its rules.
+func (f Field) fixture() (string, bool) {
+	for _, v := range f.candidates() {
+		if f.passes(v) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// candidates returns values for f to pick a fixture from, the most
+// descriptive first.
+func (f Field) candidates() []string {
+	if len(f.OneOf) > 0 {
+		return f.OneOf
+	}
+	switch f.Type {
+	case "bool":
+		return []string{"false", "true"}
+	case "string":
+		pad := func(local, domain string) string {
+			for f.Min != nil && float64(utf8.RuneCountInString(local+domain)) < *f.Min {
+				local += "x"
+			}
+			return local + domain
+		}
+		var out []string
+		if f.Email {
+			out = append(out, pad(f.File, "@example.com"), pad("a", "@example.com"), pad("a", "@x"))
+		} else {
+			v := pad("test-"+f.Path, "")
+			if f.Max != nil && float64(len(v)) > *f.Max {
+				v = v[:max(int(*f.Max), 0)]
+			}
+			out = append(out, v)
+		}
+		return append(out, "")
+	}
+	var nums []float64
+	if f.Min != nil {
+		nums = append(nums, *f.Min, *f.Min+1)
+	}
+	nums = append(nums, 1)
+	if f.Max != nil {
+		nums = append(nums, *f.Max, *f.Max-1)
+	}
+	var out []string
+	for _, n := range append(nums, 0) {
+		out = append(out, strconv.FormatFloat(n, 'f', -1, 64))
+	}
+	return out
+}
+
+// passes reports whether v passes the rules of f, like the checks the
+// generated Validate method runs.
+func (f Field) passes(v string) bool {
+	var n float64
+	switch f.Type {
+	case "bool":
+		return !f.Required || v == "true"
+	case "string":
+		if f.Required && v == "" {
+			return false
+		}
+		if _, err := mail.ParseAddress(v); f.Email && v != "" && err != nil {
+			return false
+		}
+		n = float64(utf8.RuneCountInString(v))
+	default:
+		var err error
+		if n, err = strconv.ParseFloat(v, 64); err != nil || (f.Required && n == 0) {
+			return false
+		}
+	}
+	return (f.Min == nil || n >= *f.Min) && (f.Max == nil || n <= *f.Max)
+}
+
+// Fields are the fields of a generated model.
+type Fields []Field
+
+// Uses reports whether the generated model needs the package imported as
+// path.
+func (fs Fields) Uses(path string) bool {
+	for _, f := range fs {
+		switch path {
+		case "errors":
+			if f.Required || f.Min != nil || f.Max != nil || f.Email || len(f.OneOf) > 0 {
+				return true
+			}
+		case "net/mail":
+			if f.Email {
+				return true
+			}
+		case "slices":
+			if len(f.OneOf) > 0 {
+				return true
+			}
+		case "unicode/utf8":
+			if f.Type == "string" && (f.Min != nil || f.Max != nil) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Width is the length of the longest field name, for aligning fields.
+func (fs Fields) Width() int {
+	width := 0
+	for _, f := range fs {
+		width = max(width, len(f.Name))
+	}
+	return width
+}
+
+// TypeWidth is the length of the longest field type, for aligning tags.
+func (fs Fields) TypeWidth() int {
+	width := 0
+	for _, f := range fs {
+		width = max(width, len(f.Type))
+	}
+	return width
+}
+
+// hasMethod reports whether the Go source src declares method name on typ.
+func hasMethod(src []byte, typ, name string) bool {
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, parser.SkipObjectResolution)
+	if err != nil {
+		return false
+	}
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || fn.Name.Name != name || len(fn.Recv.List) != 1 {
+			continue
+		}
+		recv := fn.Recv.List[0].Type
+		if star, ok := recv.(*ast.StarExpr); ok {
+			recv = star.X
+		}
+		if isIdent(recv, typ) {
+			return true
+		}
+	}
+	return false
+}
+
+// Label is the name of f in words, for headings and form labels.
+func (f Field) Label() string {
+	return label(f.Path)
+}
+
+// Text returns a Go expression formatting the field of recv as a string.
+func (f Field) Text(recv string) string {
+	x := recv + "." + f.Name
+	switch f.Type {
+	case "int":
+		return "strconv.Itoa(" + x + ")"
+	case "int64":
+		return "strconv.FormatInt(" + x + ", 10)"
+	case "float64":
+		return "strconv.FormatFloat(" + x + ", 'g', -1, 64)"
+	case "bool":
+		return "strconv.FormatBool(" + x + ")"
+	}
+	return x
+}
+
+// InputType is the type of the HTML input editing f.
+func (f Field) InputType() string {
+	switch {
+	case f.Type == "bool":
+		return "checkbox"
+	case f.Type != "string":
+		return "number"
+	case f.Email:
+		return "email"
+	}
+	return "text"
+}
+
+// Editable returns the fields a form edits, which is all but the ID.
+func (fs Fields) Editable() Fields {
+	var out Fields
+	for _, f := range fs {
+		if f.Name != "ID" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// idField is the primary key every resource model has.
+var idField = Field{
+	Names: Names{Name: "ID", Var: "id", File: "id", Package: "id", Path: "id"},
+	Type:  "int64",
+}
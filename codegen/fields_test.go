@@ -0,0 +1,170 @@
+package codegen
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func float(n float64) *float64 {
+	return &n
+}
+
+func TestParseFields(t *testing.T) {
+	tests := []struct {
+		spec string
+		want Field
+	}{
+		{"title:string", Field{Type: "string"}},
+		{"title:string:required,max=100", Field{Type: "string", Required: true, Max: float(100)}},
+		{"views:int:min=0", Field{Type: "int", Min: float(0)}},
+		{"price:float64:min=0.5,max=9.99", Field{Type: "float64", Min: float(0.5), Max: float(9.99)}},
+		{"email:string:required, email", Field{Type: "string", Required: true, Email: true}},
+		{"status:string:oneof=draft|published", Field{Type: "string", OneOf: []string{"draft", "published"}}},
+		{"rank:int64:oneof=1|2|3", Field{Type: "int64", OneOf: []string{"1", "2", "3"}}},
+		{"done:bool:required", Field{Type: "bool", Required: true}},
+	}
+	for _, tt := range tests {
+		fields, err := ParseFields([]string{tt.spec})
+		if err != nil {
+			t.Errorf("ParseFields(%q): %v", tt.spec, err)
+			continue
+		}
+		tt.want.Names, _ = names(strings.Split(tt.spec, ":")[0])
+		if !reflect.DeepEqual(fields[0], tt.want) {
+			t.Errorf("ParseFields(%q) = %+v, want %+v", tt.spec, fields[0], tt.want)
+		}
+	}
+}
+
+func TestParseFieldsInvalid(t *testing.T) {
+	tests := []struct {
+		specs  []string
+		reason string
+	}{
+		{[]string{"title"}, "want name:type[:rules]"},
+		{[]string{"title:text"}, `unsupported type "text"`},
+		{[]string{"title:string", "Title:string"}, "listed twice"},
+		{[]string{"type:string"}, "Go keyword"},
+		{[]string{"title:string:min=5,max=2"}, "min is greater than max"},
+		{[]string{"count:int:email"}, "email needs a string field"},
+		{[]string{"title:string:unique"}, `unknown rule "unique"`},
+		{[]string{"done:bool:max=1"}, "max needs a string or number field"},
+		{[]string{"count:int:min=1.5"}, "min=1.5: not a valid bound"},
+		{[]string{"title:string:max=x"}, "max=x: not a valid bound"},
+		{[]string{"count:int:oneof=a|b"}, `oneof: "a" is not an integer`},
+		{[]string{"title:string:oneof="}, "oneof needs a string or number field and values"},
+		{[]string{"title:string:required,max=0"}, "no string value passes required,max=0"},
+		{[]string{"count:int:required,min=0,max=0"}, "no int value passes"},
+		{[]string{"title:string:required,email,max=2"}, "no string value passes"},
+		{[]string{"title:string:email,oneof=a|b"}, "no string value passes"},
+	}
+	for _, tt := range tests {
+		_, err := ParseFields(tt.specs)
+		if err == nil || !strings.Contains(err.Error(), tt.reason) {
+			t.Errorf("ParseFields(%q): got error %v, want %q", tt.specs, err, tt.reason)
+		}
+	}
+}
+
+func TestFieldChecks(t *testing.T) {
+	fields, err := ParseFields([]string{"email:string:required,email,max=40", "count:int:min=1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range fields {
+		for _, c := range f.Checks("m") {
+			got = append(got, strings.TrimPrefix(c.Init+"; ", "; ")+c.Broken+" // "+c.Message)
+		}
+	}
+	want := []string{
+		`m.Email == "" // email is required`,
+		`utf8.RuneCountInString(m.Email) > 40 // email must be at most 40 characters`,
+		`_, err := mail.ParseAddress(m.Email); m.Email != "" && err != nil // email must be an email address`,
+		`m.Count < 1 // count must be at least 1`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("checks =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// fixtureRules are rule sets whose fixtures TestFixturesValidate checks.
+var fixtureRules = []string{
+	"a:string",
+	"a:string:required",
+	"a:string:required,min=30",
+	"a:string:max=3",
+	"a:string:required,max=1",
+	"a:string:email",
+	"a:string:required,email,max=4",
+	"a:string:required,email,min=40",
+	"a:string:email,max=2",
+	"a:string:oneof=x|draft,min=2",
+	"a:string:oneof=a@b|nope,email,required",
+	"a:int",
+	"a:int:required",
+	"a:int:required,min=0",
+	"a:int:required,min=-1,max=0",
+	"a:int:min=5,max=5",
+	"a:int64:max=-3",
+	"a:int:oneof=0|7,required",
+	"a:float64:required,min=0.5,max=0.7",
+	"a:float64:max=0",
+	"a:bool",
+	"a:bool:required",
+}
+
+func TestFixturesValidate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles a Go program")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not installed")
+	}
+	dir := t.TempDir()
+	var main strings.Builder
+	main.WriteString("package main\n\nimport (\n\t\"fmt\"\n\n\t\"example.com/app/model\"\n)\n\nfunc main() {\n")
+	for i, spec := range fixtureRules {
+		fields, err := ParseFields([]string{spec})
+		if err != nil {
+			t.Fatalf("ParseFields(%q): %v", spec, err)
+		}
+		n, _ := names(fmt.Sprintf("m%d", i))
+		data := Data{Names: n, Module: "example.com/app", Fields: fields}
+		for file, tmpl := range map[string]string{n.File + ".go": "model.go.tmpl", n.File + "_fixture.go": "fixture.go.tmpl"} {
+			src, err := render(tmpl, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			writeFile(t, filepath.Join(dir, "model", file), src)
+		}
+		fmt.Fprintf(&main, "\tif err := model.New%sFixture().Validate(); err != nil {\n\t\tfmt.Printf(\"%%q: %%v\\n\", %q, err)\n\t}\n", n.Name, spec)
+	}
+	main.WriteString("}\n")
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/app\n\ngo 1.22\n")
+	writeFile(t, filepath.Join(dir, "main.go"), main.String())
+
+	cmd := exec.Command(goBin, "run", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off")
+	out, err := cmd.CombinedOutput()
+	if err != nil || len(out) > 0 {
+		t.Errorf("fixtures break their rules: %v\n%s", err, out)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,422 @@
+package codegen
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/cagrigit-hub/golosus/scaffold"
+)
+
+//go:embed templates
+var templates embed.FS
+
+// Data holds the variables generator templates are rendered with.
+type Data struct {
+	Names
+	Module string
+
+	// Routes are the routes a generated handler serves.
+	Routes []Route
+	// Renders is set when some route renders the view of the handler.
+	Renders bool
+	// Form is the route the form of a generated view submits to, if any.
+	Form *Route
+
+	// Props are the fields of the props struct of a generated component.
+	Props Props
+
+	// Fields are the fields of a generated model.
+	Fields Fields
+	// Validates is set when the model of a generated handler has a Validate
+	// method.
+	Validates bool
+
+	// Plural are the spellings of the plural of a resource name.
+	Plural Names
+}
+
+// Recv is the receiver name of methods on the generated type.
+func (d Data) Recv() string {
+	return strings.ToLower(d.Name[:1])
+}
+
+// HasPropType reports whether any prop has one of types.
+func (d Data) HasPropType(types ...string) bool {
+	for _, p := range d.Props {
+		if slices.Contains(types, p.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Workspace) data(name string) (Data, error) {
+	n, err := names(name)
+	if err != nil {
+		return Data{}, err
+	}
+	return Data{Names: n, Module: w.Module}, nil
+}
+
+func render(name string, data any) (string, error) {
+	tmpl, err := template.New(name).Funcs(template.FuncMap{
+		"lower": strings.ToLower,
+		"add":   func(a, b int) int { return a + b },
+	}).ParseFS(templates, "templates/"+name)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Handler plans handler/<name>.go with a <Name>Handler method for each
+// route, the view/<name> package and model.<Name> it renders, and the
+// registration of the routes in cmd/main.go. Without routes the handler
+// serves GET and POST on /<name>. An existing model is reused.
+func (w *Workspace) Handler(name string, routes []Route) (*scaffold.Project, error) {
+	if err := w.requireEcho(); err != nil {
+		return nil, err
+	}
+	data, err := w.data(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(routes) == 0 {
+		if routes, err = ParseRoutes("GET:/" + data.Path + ",POST:/" + data.Path); err != nil {
+			return nil, err
+		}
+	}
+	data.Routes = routes
+	for i, r := range routes {
+		if r.Method != "DELETE" {
+			data.Renders = true
+		}
+		if data.Form == nil && r.Method != "GET" && r.Method != "DELETE" && !strings.ContainsAny(r.Path, ":*") {
+			data.Form = &routes[i]
+		}
+	}
+
+	files := map[string]string{
+		"handler/" + data.File + ".go": "handler.go.tmpl",
+	}
+	if data.Renders {
+		files["view/"+data.Package+"/"+data.File+".templ"] = "view.templ.tmpl"
+		model := "model/" + data.File + ".go"
+		if src, err := w.read(model); err == nil {
+			data.Validates = hasMethod(src, data.Name, "Validate")
+		} else {
+			files[model] = "model.go.tmpl"
+			files["model/"+data.File+"_fixture.go"] = "fixture.go.tmpl"
+			data.Validates = true
+		}
+	}
+	p, err := w.plan(files, data)
+	if err != nil {
+		return nil, err
+	}
+
+	edit := &routeEdit{Module: w.Module, Handler: data.Name + "Handler", Var: data.Var + "Handler", Routes: routes}
+	main, err := w.edit("cmd/main.go", edit.apply)
+	if err != nil {
+		return nil, err
+	}
+	p.Add("cmd/main.go", main)
+	return p, nil
+}
+
+// Component plans view/components/<name>.templ with a typed props struct,
+// their defaults and a test rendering the component.
+func (w *Workspace) Component(name string, props Props) (*scaffold.Project, error) {
+	data, err := w.data(name)
+	if err != nil {
+		return nil, err
+	}
+	data.Props = props
+	return w.plan(map[string]string{
+		"view/components/" + data.File + ".templ":   "component.templ.tmpl",
+		"view/components/" + data.File + "_test.go": "component_test.go.tmpl",
+	}, data)
+}
+
+// Model plans model/<name>.go with a struct tagged for json, form binding
+// and db, a Validate method checking the rules of its fields and a fixture
+// helper for tests.
+func (w *Workspace) Model(name string, fields Fields) (*scaffold.Project, error) {
+	data, err := w.data(name)
+	if err != nil {
+		return nil, err
+	}
+	data.Fields = fields
+	return w.plan(map[string]string{
+		"model/" + data.File + ".go":         "model.go.tmpl",
+		"model/" + data.File + "_fixture.go": "fixture.go.tmpl",
+	}, data)
+}
+
+// Resource plans a CRUD resource: model.<Name> with an ID and fields, a
+// repository interface with an in-memory implementation, a handler with
+// index, show, new, create, edit, update and delete actions, templ views
+// swapping table rows with htmx, and the routes under /<names> in
+// cmd/main.go.
+func (w *Workspace) Resource(name string, fields Fields) (*scaffold.Project, error) {
+	if err := w.requireEcho(); err != nil {
+		return nil, err
+	}
+	data, err := w.data(name)
+	if err != nil {
+		return nil, err
+	}
+	if data.Plural, err = names(plural(data.Name)); err != nil {
+		return nil, err
+	}
+	data.Fields = Fields{idField}
+	for _, f := range fields {
+		if f.Name != "ID" {
+			data.Fields = append(data.Fields, f)
+		}
+	}
+	base, item := "/"+data.Plural.Path, "/"+data.Plural.Path+"/:id"
+	data.Routes = []Route{
+		{Method: "GET", Path: base, Func: "HandleIndex"},
+		{Method: "GET", Path: base + "/new", Func: "HandleNew"},
+		{Method: "POST", Path: base, Func: "HandleCreate"},
+		{Method: "GET", Path: item, Func: "HandleShow"},
+		{Method: "GET", Path: item + "/edit", Func: "HandleEdit"},
+		{Method: "PUT", Path: item, Func: "HandleUpdate"},
+		{Method: "DELETE", Path: item, Func: "HandleDelete"},
+	}
+
+	files := map[string]string{
+		"model/" + data.File + ".go":                        "model.go.tmpl",
+		"model/" + data.File + "_fixture.go":                "fixture.go.tmpl",
+		"repository/" + data.File + ".go":                   "resource_repository.go.tmpl",
+		"handler/" + data.File + ".go":                      "resource_handler.go.tmpl",
+		"view/" + data.Package + "/" + data.File + ".templ": "resource_view.templ.tmpl",
+	}
+	if !w.exists("repository/repository.go") {
+		files["repository/repository.go"] = "repository.go.tmpl"
+	}
+	p, err := w.plan(files, data)
+	if err != nil {
+		return nil, err
+	}
+
+	edit := &routeEdit{
+		Module:  w.Module,
+		Handler: data.Name + "Handler",
+		Var:     data.Var + "Handler",
+		Fields:  "Repo: repository.NewMemory" + data.Name + "Repository()",
+		Imports: []string{w.Module + "/repository"},
+		Routes:  data.Routes,
+	}
+	main, err := w.edit("cmd/main.go", edit.apply)
+	if err != nil {
+		return nil, err
+	}
+	p.Add("cmd/main.go", main)
+	return p, nil
+}
+
+// pageData holds the variables page templates are rendered with.
+type pageData struct {
+	Data
+	// Layout is the layout component the page is wrapped in.
+	Layout string
+	Title  string
+	// View is the directory of the view package, e.g. view/admin/users.
+	View string
+	// Func is the PageHandler method serving the page.
+	Func string
+}
+
+// pagePackages are the packages a page view may not be named after: the
+// packages of the scaffold and the packages page files import.
+var pagePackages = map[string]string{
+	"layout":     "view/layout",
+	"components": "view/components",
+	"handler":    "handler",
+	"model":      "model",
+	"echo":       "github.com/labstack/echo/v4",
+}
+
+// Page plans a page at the URL path path, e.g. admin/users: the view package
+// view/admin/users wrapped in layout, a PageHandler method rendering it, its
+// GET route in cmd/main.go and a link in view/layout/nav.go. The first
+// segment of a nested path becomes a route group. Pages whose view package
+// would be named like one of pagePackages are refused.
+func (w *Workspace) Page(path, layout string) (*scaffold.Project, error) {
+	if err := w.requireEcho(); err != nil {
+		return nil, err
+	}
+	var segs []Names
+	for _, seg := range strings.Split(strings.Trim(path, "/"), "/") {
+		n, err := names(seg)
+		if err != nil {
+			return nil, err
+		}
+		segs = append(segs, n)
+	}
+	layouts, err := w.Layouts()
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(layouts, layout) {
+		return nil, fmt.Errorf("no layout %q in view/layout, available: %s", layout, strings.Join(layouts, ", "))
+	}
+
+	var dirs, parts []string
+	for _, n := range segs {
+		dirs = append(dirs, n.Package)
+		parts = append(parts, n.Path)
+	}
+	full, err := names(strings.Join(parts, "-"))
+	if err != nil {
+		return nil, err
+	}
+	last := segs[len(segs)-1]
+	if pkg, ok := pagePackages[last.Package]; ok {
+		return nil, fmt.Errorf("page %q would be package %s, which collides with %s; choose another name", path, last.Package, pkg)
+	}
+	data := pageData{
+		Data:   Data{Names: last, Module: w.Module},
+		Layout: layout,
+		Title:  label(last.Path),
+		View:   "view/" + strings.Join(dirs, "/"),
+		Func:   "Handle" + full.Name + "Page",
+	}
+	files := map[string]string{
+		data.View + "/" + last.File + ".templ": "page.templ.tmpl",
+		"handler/" + full.File + "_page.go":    "page.go.tmpl",
+	}
+	if !w.exists("handler/page.go") {
+		files["handler/page.go"] = "pages.go.tmpl"
+	}
+	p, err := w.plan(files, data)
+	if err != nil {
+		return nil, err
+	}
+
+	href := "/" + strings.Join(parts, "/")
+	edit := &routeEdit{
+		Module:  w.Module,
+		Handler: "PageHandler",
+		Var:     "pageHandler",
+		Routes:  []Route{{Method: "GET", Path: href, Func: data.Func}},
+	}
+	if len(segs) > 1 {
+		edit.Group, edit.GroupVar = "/"+segs[0].Path, segs[0].Var+"Group"
+		edit.Routes[0].Path = strings.TrimPrefix(href, edit.Group)
+	}
+	main, err := w.edit("cmd/main.go", edit.apply)
+	if err != nil {
+		return nil, err
+	}
+	p.Add("cmd/main.go", main)
+
+	nav := &navEdit{Label: data.Title, Href: href}
+	if w.exists("view/layout/nav.go") {
+		src, err := w.edit("view/layout/nav.go", nav.apply)
+		if err != nil {
+			return nil, err
+		}
+		p.Add("view/layout/nav.go", src)
+	} else {
+		for name, tmpl := range map[string]string{"view/layout/nav.go": "nav.go.tmpl", "view/layout/nav.templ": "nav.templ.tmpl"} {
+			src, err := render(tmpl, data)
+			if err != nil {
+				return nil, err
+			}
+			if name == "view/layout/nav.go" {
+				out, err := nav.apply([]byte(src))
+				if err != nil {
+					return nil, err
+				}
+				src = string(out)
+			}
+			p.Add(name, src)
+		}
+	}
+	return p, nil
+}
+
+var layoutRe = regexp.MustCompile(`(?m)^templ ([A-Za-z_]\w*)\(\)`)
+
+// Layouts returns the layouts declared in view/layout, which are the templ
+// components there that take no arguments, such as Base.
+func (w *Workspace) Layouts() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(w.Root, "view", "layout", "*.templ"))
+	if err != nil {
+		return nil, err
+	}
+	var layouts []string
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range layoutRe.FindAllSubmatch(src, -1) {
+			layouts = append(layouts, string(m[1]))
+		}
+	}
+	sort.Strings(layouts)
+	return layouts, nil
+}
+
+// Feature plans the files of a feature of the built-in stack.
+func (w *Workspace) Feature(name string) (*scaffold.Project, error) {
+	m, err := scaffold.LoadManifest(scaffold.Templates())
+	if err != nil {
+		return nil, err
+	}
+	f, ok := m.Features[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown feature %q, available: %s", name, strings.Join(Features(m), ", "))
+	}
+	p, err := scaffold.New(w.Data())
+	if err != nil {
+		return nil, err
+	}
+	sub := p.Subset(f.Paths)
+	sub.Data.Name = w.project().Data.Name
+	return sub, nil
+}
+
+// Features returns the sorted feature names of m.
+func Features(m *scaffold.Manifest) []string {
+	var out []string
+	for name := range m.Features {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// plan renders templates, keyed by output path, into a project.
+func (w *Workspace) plan(files map[string]string, data any) (*scaffold.Project, error) {
+	p := w.project()
+	out := make([]string, 0, len(files))
+	for name := range files {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	for _, name := range out {
+		content, err := render(files[name], data)
+		if err != nil {
+			return nil, err
+		}
+		p.Add(name, content)
+	}
+	return p, nil
+}
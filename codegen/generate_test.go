@@ -0,0 +1,107 @@
+package codegen
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPageCollisions(t *testing.T) {
+	w := scaffolded(t, nil)
+	for _, path := range []string{"layout", "echo", "components", "admin/handler", "Model"} {
+		_, err := w.Page(path, "Base")
+		if err == nil || !strings.Contains(err.Error(), "collides with") {
+			t.Errorf("Page(%q): got error %v, want a collision", path, err)
+		}
+	}
+	if _, err := w.Page("layout/settings", "Base"); err != nil {
+		t.Errorf("Page(layout/settings): %v", err)
+	}
+}
+
+func TestResource(t *testing.T) {
+	w := scaffolded(t, nil)
+	fields, err := ParseFields([]string{"title:string:required,max=100", "views:int:min=0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := w.Resource("BlogPost", fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"cmd/main.go",
+		"handler/blog_post.go",
+		"model/blog_post.go",
+		"model/blog_post_fixture.go",
+		"repository/blog_post.go",
+		"repository/repository.go",
+		"view/blogpost/blog_post.templ",
+	}
+	got := p.Paths()
+	slices.Sort(got)
+	if !slices.Equal(got, want) {
+		t.Errorf("planned %q, want %q", got, want)
+	}
+
+	fset := token.NewFileSet()
+	for _, name := range got {
+		if !strings.HasSuffix(name, ".go") {
+			continue
+		}
+		src, _ := p.File(name)
+		if _, err := parser.ParseFile(fset, name, src, parser.AllErrors); err != nil {
+			t.Errorf("%s does not parse: %v\n%s", name, err, src)
+		}
+	}
+
+	main, _ := p.File("cmd/main.go")
+	for _, line := range []string{
+		`"example.com/app/repository"`,
+		"blogPostHandler := &handler.BlogPostHandler{Repo: repository.NewMemoryBlogPostRepository()}",
+		`app.GET("/blog-posts", blogPostHandler.HandleIndex)`,
+		`app.GET("/blog-posts/new", blogPostHandler.HandleNew)`,
+		`app.POST("/blog-posts", blogPostHandler.HandleCreate)`,
+		`app.GET("/blog-posts/:id", blogPostHandler.HandleShow)`,
+		`app.GET("/blog-posts/:id/edit", blogPostHandler.HandleEdit)`,
+		`app.PUT("/blog-posts/:id", blogPostHandler.HandleUpdate)`,
+		`app.DELETE("/blog-posts/:id", blogPostHandler.HandleDelete)`,
+	} {
+		if !strings.Contains(main, line) {
+			t.Errorf("cmd/main.go lacks %s:\n%s", line, main)
+		}
+	}
+	if !w.Edited("cmd/main.go") {
+		t.Errorf("cmd/main.go is not recorded as edited")
+	}
+
+	model, _ := p.File("model/blog_post.go")
+	for _, line := range []string{"ID    int64", "Title string", "Views int", "func (b BlogPost) Validate() error"} {
+		if !strings.Contains(model, line) {
+			t.Errorf("model/blog_post.go lacks %s:\n%s", line, model)
+		}
+	}
+	handler, _ := p.File("handler/blog_post.go")
+	if !strings.Contains(handler, `"example.com/app/view/blogpost"`) {
+		t.Errorf("handler/blog_post.go does not import its view:\n%s", handler)
+	}
+
+	if _, err := w.Write(context.Background(), p); err != nil {
+		t.Fatal(err)
+	}
+	again, err := w.Resource("BlogPost", fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := w.Write(context.Background(), again)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Written) > 0 {
+		t.Errorf("generating the resource again wrote %q", report.Written)
+	}
+}
@@ -0,0 +1,95 @@
+package codegen
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"strings"
+	"unicode"
+
+	"github.com/cagrigit-hub/golosus/scaffold"
+)
+
+// words splits names like "UserProfile", "user_profile" or "user-profile"
+// into lower case words, by the rules project names follow.
+func words(name string) ([]string, error) {
+	ws, invalid := scaffold.SplitWords(name)
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("invalid name %q: unsupported characters %s", name, strings.Join(invalid, ", "))
+	}
+	if len(ws) == 0 {
+		return nil, fmt.Errorf("invalid name %q", name)
+	}
+	if unicode.IsDigit(rune(ws[0][0])) {
+		return nil, fmt.Errorf("invalid name %q: must start with a letter", name)
+	}
+	for i, w := range ws {
+		ws[i] = strings.ToLower(w)
+	}
+	return ws, nil
+}
+
+// Names are the spellings of one generated thing.
+type Names struct {
+	// Name is the exported Go identifier, e.g. UserProfile.
+	Name string
+	// Var is the unexported Go identifier, e.g. userProfile.
+	Var string
+	// File is the file name without extension, e.g. user_profile.
+	File string
+	// Package is the Go package name, e.g. userprofile.
+	Package string
+	// Path is the URL path segment, e.g. user-profile.
+	Path string
+}
+
+// names returns the spellings of name. Names whose package name would be a
+// Go keyword or predeclared identifier are rejected since the generated code
+// would not compile.
+func names(name string) (Names, error) {
+	ws, err := words(name)
+	if err != nil {
+		return Names{}, err
+	}
+	pkg := strings.Join(ws, "")
+	switch {
+	case token.IsKeyword(pkg):
+		return Names{}, fmt.Errorf("invalid name %q: %s is a Go keyword", name, pkg)
+	case types.Universe.Lookup(pkg) != nil:
+		return Names{}, fmt.Errorf("invalid name %q: %s is a predeclared Go identifier", name, pkg)
+	case pkg == "main":
+		return Names{}, fmt.Errorf("invalid name %q: package main cannot be imported", name)
+	}
+	var pascal strings.Builder
+	for _, w := range ws {
+		pascal.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	return Names{
+		Name:    pascal.String(),
+		Var:     ws[0] + strings.TrimPrefix(pascal.String(), strings.ToUpper(ws[0][:1])+ws[0][1:]),
+		File:    strings.Join(ws, "_"),
+		Package: pkg,
+		Path:    strings.Join(ws, "-"),
+	}, nil
+}
+
+// label returns the words of a URL path segment as a capitalized label, e.g.
+// "User list" for user-list.
+func label(path string) string {
+	label := strings.ReplaceAll(path, "-", " ")
+	return strings.ToUpper(label[:1]) + label[1:]
+}
+
+// plural returns the English plural of the last word of name, good enough
+// for resource names.
+func plural(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return name[:len(name)-1] + "ies"
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return name + "es"
+	}
+	return name + "s"
+}
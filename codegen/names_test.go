@@ -0,0 +1,54 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNames(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Names
+	}{
+		{"UserProfile", Names{Name: "UserProfile", Var: "userProfile", File: "user_profile", Package: "userprofile", Path: "user-profile"}},
+		{"user_profile", Names{Name: "UserProfile", Var: "userProfile", File: "user_profile", Package: "userprofile", Path: "user-profile"}},
+		{"HTTPServer", Names{Name: "HttpServer", Var: "httpServer", File: "http_server", Package: "httpserver", Path: "http-server"}},
+		{"user", Names{Name: "User", Var: "user", File: "user", Package: "user", Path: "user"}},
+		{"My Page", Names{Name: "MyPage", Var: "myPage", File: "my_page", Package: "mypage", Path: "my-page"}},
+	}
+	for _, tt := range tests {
+		got, err := names(tt.in)
+		if err != nil {
+			t.Errorf("names(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("names(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNamesInvalid(t *testing.T) {
+	tests := []struct {
+		in, reason string
+	}{
+		{"Type", "type is a Go keyword"},
+		{"default", "default is a Go keyword"},
+		{"Func", "func is a Go keyword"},
+		{"map", "map is a Go keyword"},
+		{"go-to", "goto is a Go keyword"},
+		{"String", "string is a predeclared Go identifier"},
+		{"len", "len is a predeclared Go identifier"},
+		{"Nil", "nil is a predeclared Go identifier"},
+		{"main", "package main cannot be imported"},
+		{"1user", "must start with a letter"},
+		{"user!", `unsupported characters '!'`},
+		{"", "invalid name"},
+	}
+	for _, tt := range tests {
+		_, err := names(tt.in)
+		if err == nil || !strings.Contains(err.Error(), tt.reason) {
+			t.Errorf("names(%q): got error %v, want %q", tt.in, err, tt.reason)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package codegen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strconv"
+)
+
+// navEdit adds a link to the navigation bar declared in view/layout/nav.go.
+type navEdit struct {
+	Label, Href string
+}
+
+// apply returns src with a Link to Href appended to the Links variable,
+// unless one is already there.
+func (e *navEdit) apply(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "view/layout/nav.go", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	links := navLinks(f)
+	if links == nil {
+		return nil, errors.New("view/layout/nav.go: no var Links = []Link{...}, add the link by hand")
+	}
+	for _, elt := range links.Elts {
+		if navHref(elt) == e.Href {
+			return src, nil
+		}
+	}
+
+	link := fmt.Sprintf("{Label: %q, Href: %q}", e.Label, e.Href)
+	rbrace := fset.Position(links.Rbrace)
+	var out []byte
+	if len(links.Elts) > 0 && fset.Position(links.Lbrace).Line == rbrace.Line {
+		out = splice(src, rbrace.Offset, ", "+link)
+	} else {
+		at := bytes.LastIndexByte(src[:rbrace.Offset], '\n') + 1
+		out = splice(src, at, link+",\n")
+	}
+	formatted, err := format.Source(out)
+	if err != nil {
+		return nil, fmt.Errorf("view/layout/nav.go: adding link: %w", err)
+	}
+	return formatted, nil
+}
+
+func navLinks(f *ast.File) *ast.CompositeLit {
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			for i, name := range vs.Names {
+				if name.Name != "Links" || i >= len(vs.Values) {
+					continue
+				}
+				if lit, ok := vs.Values[i].(*ast.CompositeLit); ok {
+					return lit
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// navHref returns the Href of a Link literal.
+func navHref(elt ast.Expr) string {
+	lit, ok := elt.(*ast.CompositeLit)
+	if !ok {
+		return ""
+	}
+	for _, e := range lit.Elts {
+		kv, ok := e.(*ast.KeyValueExpr)
+		if !ok || !isIdent(kv.Key, "Href") {
+			continue
+		}
+		if v, ok := kv.Value.(*ast.BasicLit); ok && v.Kind == token.STRING {
+			href, _ := strconv.Unquote(v.Value)
+			return href
+		}
+	}
+	return ""
+}
@@ -0,0 +1,147 @@
+package codegen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PropTypes are the Go types component props can have.
+var PropTypes = []string{"string", "bool", "int", "float64", "[]string"}
+
+// Prop is a field of the props struct of a component.
+type Prop struct {
+	Names
+	Type string
+	// Default is the Go literal the field defaults to, empty for the zero
+	// value.
+	Default string
+}
+
+// Props are the fields of a props struct.
+type Props []Prop
+
+// Width is the length of the longest prop name, for aligning fields.
+func (ps Props) Width() int {
+	width := 0
+	for _, p := range ps {
+		width = max(width, len(p.Name))
+	}
+	return width
+}
+
+// Defaults returns the props with a default value.
+func (ps Props) Defaults() Props {
+	var out Props
+	for _, p := range ps {
+		if p.Default != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// ParseProps parses a comma separated list of Name:type[=default] props, e.g.
+// "Label:string=Submit,Disabled:bool". Strings without a default default to
+// their name in words.
+func ParseProps(s string) (Props, error) {
+	var props Props
+	seen := map[string]bool{}
+	for _, spec := range strings.Split(s, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		name, typ, ok := strings.Cut(spec, ":")
+		if !ok {
+			return nil, fmt.Errorf("prop %q: want Name:type", spec)
+		}
+		typ, def, hasDefault := strings.Cut(typ, "=")
+		n, err := names(name)
+		if err != nil {
+			return nil, fmt.Errorf("prop %q: %w", spec, err)
+		}
+		if seen[n.Name] {
+			return nil, fmt.Errorf("prop %s: listed twice", n.Name)
+		}
+		seen[n.Name] = true
+		p := Prop{Names: n, Type: strings.TrimSpace(typ)}
+		if !hasDefault && p.Type == "string" {
+			def, hasDefault = strings.ReplaceAll(n.Path, "-", " "), true
+			def = strings.ToUpper(def[:1]) + def[1:]
+		}
+		if p.Default, err = literal(p.Type, def, hasDefault); err != nil {
+			return nil, fmt.Errorf("prop %s: %w", n.Name, err)
+		}
+		props = append(props, p)
+	}
+	return props, nil
+}
+
+// literal returns the Go literal for the default value def of a prop of type
+// typ.
+func literal(typ, def string, set bool) (string, error) {
+	switch typ {
+	case "string":
+		if set {
+			return strconv.Quote(def), nil
+		}
+		return "", nil
+	case "bool":
+		if !set {
+			return "", nil
+		}
+		v, err := strconv.ParseBool(def)
+		return strconv.FormatBool(v), err
+	case "int":
+		if !set {
+			return "", nil
+		}
+		v, err := strconv.Atoi(def)
+		return strconv.Itoa(v), err
+	case "float64":
+		if !set {
+			return "", nil
+		}
+		v, err := strconv.ParseFloat(def, 64)
+		return strconv.FormatFloat(v, 'g', -1, 64), err
+	case "[]string":
+		if set {
+			return "", fmt.Errorf("%s props cannot have a default", typ)
+		}
+		return "", nil
+	}
+	return "", fmt.Errorf("unsupported type %q, use one of %s", typ, strings.Join(PropTypes, ", "))
+}
+
+// Sample is a Go literal the generated test sets the prop to.
+func (p Prop) Sample() string {
+	switch p.Type {
+	case "string":
+		return strconv.Quote("test-" + p.Path)
+	case "bool":
+		return "true"
+	case "int":
+		return "42"
+	case "float64":
+		return "4.2"
+	case "[]string":
+		return `[]string{"test-` + p.Path + `"}`
+	}
+	return ""
+}
+
+// Want is the text the rendered component contains with the sample value.
+func (p Prop) Want() string {
+	switch p.Type {
+	case "string", "[]string":
+		return "test-" + p.Path
+	case "bool":
+		return "data-" + p.Path
+	case "int":
+		return "42"
+	case "float64":
+		return "4.2"
+	}
+	return ""
+}
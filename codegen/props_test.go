@@ -0,0 +1,91 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseProps(t *testing.T) {
+	tests := []struct {
+		spec string
+		// want are the types and defaults of the props, in order.
+		want [][2]string
+	}{
+		{"Label:string", [][2]string{{"string", `"Label"`}}},
+		{"HelpText:string", [][2]string{{"string", `"Help text"`}}},
+		{"Label:string=Send it", [][2]string{{"string", `"Send it"`}}},
+		{`Label:string=say "hi"`, [][2]string{{"string", `"say \"hi\""`}}},
+		{"Label:string=", [][2]string{{"string", `""`}}},
+		{"Disabled:bool", [][2]string{{"bool", ""}}},
+		{"Disabled:bool=true", [][2]string{{"bool", "true"}}},
+		{"Disabled:bool=1", [][2]string{{"bool", "true"}}},
+		{"Count:int", [][2]string{{"int", ""}}},
+		{"Count:int=07", [][2]string{{"int", "7"}}},
+		{"Ratio:float64", [][2]string{{"float64", ""}}},
+		{"Ratio:float64=0.50", [][2]string{{"float64", "0.5"}}},
+		{"Items:[]string", [][2]string{{"[]string", ""}}},
+		{" Label:string=Go , Disabled:bool ,", [][2]string{{"string", `"Go"`}, {"bool", ""}}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		props, err := ParseProps(tt.spec)
+		if err != nil {
+			t.Errorf("ParseProps(%q): %v", tt.spec, err)
+			continue
+		}
+		var got [][2]string
+		for _, p := range props {
+			got = append(got, [2]string{p.Type, p.Default})
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("ParseProps(%q) = %q, want %q", tt.spec, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("ParseProps(%q) = %q, want %q", tt.spec, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestParsePropsInvalid(t *testing.T) {
+	tests := []struct {
+		spec, reason string
+	}{
+		{"Label", `prop "Label": want Name:type`},
+		{"Label:text", `unsupported type "text"`},
+		{":string", "invalid name"},
+		{"Label!:string", "unsupported characters '!'"},
+		{"Type:string", "Go keyword"},
+		{"Label:string,label:bool", "prop Label: listed twice"},
+		{"Disabled:bool=yes", `parsing "yes"`},
+		{"Count:int=1.5", `parsing "1.5"`},
+		{"Ratio:float64=half", `parsing "half"`},
+		{"Items:[]string=a", "[]string props cannot have a default"},
+	}
+	for _, tt := range tests {
+		_, err := ParseProps(tt.spec)
+		if err == nil || !strings.Contains(err.Error(), tt.reason) {
+			t.Errorf("ParseProps(%q): got error %v, want %q", tt.spec, err, tt.reason)
+		}
+	}
+}
+
+func TestPropsDefaults(t *testing.T) {
+	props, err := ParseProps("Label:string,Disabled:bool,Size:int=2,Items:[]string")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, p := range props.Defaults() {
+		got = append(got, p.Name+"="+p.Default)
+	}
+	if want := `Label="Label" Size=2`; strings.Join(got, " ") != want {
+		t.Errorf("Defaults = %q, want %q", got, want)
+	}
+	if props.Width() != len("Disabled") {
+		t.Errorf("Width = %d, want %d", props.Width(), len("Disabled"))
+	}
+}
@@ -0,0 +1,322 @@
+package codegen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Methods are the HTTP methods routes can be registered for.
+var Methods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+
+// Route is a request a handler method serves.
+type Route struct {
+	Method string
+	Path   string
+	// Func is the name of the handler method, derived from Method and Path.
+	Func string
+}
+
+// ParseRoutes parses a comma separated list of METHOD:/path routes, e.g.
+// "GET:/users,POST:/users,DELETE:/users/:id".
+func ParseRoutes(s string) ([]Route, error) {
+	var routes []Route
+	seen := map[string]bool{}
+	for _, spec := range strings.Split(s, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		method, path, ok := strings.Cut(spec, ":")
+		if !ok {
+			return nil, fmt.Errorf("route %q: want METHOD:/path", spec)
+		}
+		r, err := newRoute(method, path)
+		if err != nil {
+			return nil, err
+		}
+		if seen[r.Method+" "+r.Path] {
+			return nil, fmt.Errorf("route %s %s: listed twice", r.Method, r.Path)
+		}
+		seen[r.Method+" "+r.Path] = true
+		routes = append(routes, r)
+	}
+	if len(routes) == 0 {
+		return nil, errors.New("no routes")
+	}
+	return routes, nil
+}
+
+func newRoute(method, path string) (Route, error) {
+	method = strings.ToUpper(method)
+	if !slices.Contains(Methods, method) {
+		return Route{}, fmt.Errorf("route %s:%s: method must be one of %s", method, path, strings.Join(Methods, ", "))
+	}
+	if !strings.HasPrefix(path, "/") {
+		return Route{}, fmt.Errorf("route %s:%s: path must start with /", method, path)
+	}
+	fn := "Handle" + method[:1] + strings.ToLower(method[1:])
+	var segments []string
+	for _, seg := range strings.Split(path, "/") {
+		switch {
+		case seg == "":
+			continue
+		case seg == "*":
+			seg = "any"
+		case strings.HasPrefix(seg, ":"):
+			seg = "by-" + seg[1:]
+		}
+		segments = append(segments, seg)
+	}
+	if len(segments) == 0 {
+		segments = []string{"index"}
+	}
+	n, err := names(strings.Join(segments, "-"))
+	if err != nil {
+		return Route{}, fmt.Errorf("route %s:%s: %w", method, path, err)
+	}
+	return Route{Method: method, Path: path, Func: fn + n.Name}, nil
+}
+
+// routeEdit registers routes in the main function of a generated
+// cmd/main.go.
+type routeEdit struct {
+	// Module is the module path of the project.
+	Module string
+	// Handler is the handler type serving the routes, e.g. UserHandler.
+	Handler string
+	// Var is the variable declared for the handler when main has none yet.
+	Var string
+	// Fields initializes the handler, e.g. "Repo: repository.NewMemoryUserRepository()".
+	Fields string
+	// Imports are the packages Fields uses.
+	Imports []string
+	// Group, when set, is the prefix of the route group the routes are
+	// registered on, e.g. /admin. GroupVar names the group when main has
+	// none for the prefix yet.
+	Group, GroupVar string
+	Routes          []Route
+}
+
+// apply returns src with a variable for the handler and every missing route
+// registered right before the server is started. Routes that are already
+// registered to the same method are left alone, so applying an edit twice
+// changes nothing. The statements are placed using the syntax tree of src;
+// a main that no longer creates the app with echo.New() and starts it in
+// func main is not touched.
+func (e *routeEdit) apply(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "cmd/main.go", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	echoName, handlerName := "", "handler"
+	imported := map[string]bool{}
+	for _, spec := range f.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := ""
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		switch path {
+		case "github.com/labstack/echo/v4":
+			echoName = orDefault(name, "echo")
+		case e.Module + "/handler":
+			handlerName = orDefault(name, "handler")
+		}
+		imported[path] = true
+	}
+
+	var main *ast.FuncDecl
+	for _, decl := range f.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == "main" && fn.Body != nil {
+			main = fn
+		}
+	}
+	if main == nil || echoName == "" {
+		return nil, e.unrecognized("no func main using github.com/labstack/echo/v4")
+	}
+
+	var (
+		app, handlerVar string
+		start           ast.Stmt
+		groups          = map[string]string{}
+		registered      = map[string]string{}
+	)
+	for _, stmt := range main.Body.List {
+		if app != "" && callsStart(stmt, app) {
+			start = stmt
+			break
+		}
+		switch s := stmt.(type) {
+		case *ast.AssignStmt:
+			if s.Tok != token.DEFINE || len(s.Lhs) != 1 || len(s.Rhs) != 1 {
+				continue
+			}
+			lhs, ok := s.Lhs[0].(*ast.Ident)
+			if !ok {
+				continue
+			}
+			if call, ok := s.Rhs[0].(*ast.CallExpr); ok {
+				switch {
+				case isSelector(call.Fun, echoName, "New") && app == "":
+					app = lhs.Name
+				case app != "" && isSelector(call.Fun, app, "Group") && len(call.Args) > 0:
+					if lit, ok := call.Args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+						prefix, _ := strconv.Unquote(lit.Value)
+						groups[prefix] = lhs.Name
+					}
+				}
+			}
+			if u, ok := s.Rhs[0].(*ast.UnaryExpr); ok && u.Op == token.AND {
+				if lit, ok := u.X.(*ast.CompositeLit); ok && isSelector(lit.Type, handlerName, e.Handler) {
+					handlerVar = lhs.Name
+				}
+			}
+		case *ast.ExprStmt:
+			call, ok := s.X.(*ast.CallExpr)
+			if !ok || app == "" {
+				continue
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || !slices.Contains(Methods, sel.Sel.Name) || len(call.Args) < 2 {
+				continue
+			}
+			recv, ok := sel.X.(*ast.Ident)
+			if lit, isLit := call.Args[0].(*ast.BasicLit); ok && isLit && lit.Kind == token.STRING {
+				path, _ := strconv.Unquote(lit.Value)
+				registered[recv.Name+" "+sel.Sel.Name+" "+path] = nodeString(fset, call.Args[len(call.Args)-1])
+			}
+		}
+	}
+	if app == "" {
+		return nil, e.unrecognized("func main does not create the app with " + echoName + ".New()")
+	}
+	if start == nil {
+		return nil, e.unrecognized("func main does not call " + app + ".Start")
+	}
+
+	var lines []string
+	if handlerVar == "" {
+		handlerVar = e.Var
+		lines = append(lines, fmt.Sprintf("%s := &%s.%s{%s}", handlerVar, handlerName, e.Handler, e.Fields))
+	}
+	recv := app
+	if e.Group != "" {
+		if recv = groups[e.Group]; recv == "" {
+			recv = e.GroupVar
+			lines = append(lines, fmt.Sprintf("%s := %s.Group(%q)", recv, app, e.Group))
+		}
+	}
+	for _, r := range e.Routes {
+		fn := handlerVar + "." + r.Func
+		if existing, ok := registered[recv+" "+r.Method+" "+r.Path]; ok {
+			if existing != fn {
+				return nil, fmt.Errorf("cmd/main.go: %s %s%s is already registered to %s", r.Method, e.Group, r.Path, existing)
+			}
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s.%s(%q, %s)", recv, r.Method, r.Path, fn))
+	}
+	if len(lines) == 0 {
+		return src, nil
+	}
+
+	// Splice the later position first so the import offset stays valid.
+	at := fset.Position(start.Pos()).Offset
+	at = bytes.LastIndexByte(src[:at], '\n') + 1
+	out := splice(src, at, strings.Join(lines, "\n")+"\n")
+	var missing []string
+	for _, path := range append([]string{e.Module + "/handler"}, e.Imports...) {
+		if !imported[path] {
+			missing = append(missing, strconv.Quote(path))
+		}
+	}
+	if len(missing) > 0 {
+		if decl := importDecl(f); decl != nil && decl.Lparen.IsValid() {
+			out = splice(out, fset.Position(decl.Lparen).Offset+1, "\n"+strings.Join(missing, "\n"))
+		} else {
+			out = splice(out, fset.Position(f.Name.End()).Offset, "\n\nimport (\n"+strings.Join(missing, "\n")+"\n)")
+		}
+	}
+	formatted, err := format.Source(out)
+	if err != nil {
+		return nil, fmt.Errorf("cmd/main.go: registering routes: %w", err)
+	}
+	return formatted, nil
+}
+
+func (e *routeEdit) unrecognized(reason string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "cmd/main.go: %s; not editing it, register the routes by hand:\n", reason)
+	fmt.Fprintf(&b, "\t%s := &handler.%s{%s}", e.Var, e.Handler, e.Fields)
+	recv := "app"
+	if e.Group != "" {
+		recv = e.GroupVar
+		fmt.Fprintf(&b, "\n\t%s := app.Group(%q)", recv, e.Group)
+	}
+	for _, r := range e.Routes {
+		fmt.Fprintf(&b, "\n\t%s.%s(%q, %s.%s)", recv, r.Method, r.Path, e.Var, r.Func)
+	}
+	return errors.New(b.String())
+}
+
+func orDefault(name, def string) string {
+	if name == "" {
+		return def
+	}
+	return name
+}
+
+func isIdent(x ast.Expr, name string) bool {
+	id, ok := x.(*ast.Ident)
+	return ok && id.Name == name
+}
+
+func isSelector(x ast.Expr, pkg, name string) bool {
+	sel, ok := x.(*ast.SelectorExpr)
+	return ok && isIdent(sel.X, pkg) && sel.Sel.Name == name
+}
+
+// callsStart reports whether stmt calls app.Start anywhere, which covers
+// both app.Start(addr) and app.Logger.Fatal(app.Start(addr)).
+func callsStart(stmt ast.Stmt, app string) bool {
+	found := false
+	ast.Inspect(stmt, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok && isSelector(call.Fun, app, "Start") {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+func importDecl(f *ast.File) *ast.GenDecl {
+	for _, decl := range f.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			return gen
+		}
+	}
+	return nil
+}
+
+func nodeString(fset *token.FileSet, n ast.Node) string {
+	var buf bytes.Buffer
+	format.Node(&buf, fset, n)
+	return buf.String()
+}
+
+func splice(src []byte, at int, text string) []byte {
+	out := make([]byte, 0, len(src)+len(text))
+	out = append(out, src[:at]...)
+	out = append(out, text...)
+	return append(out, src[at:]...)
+}
@@ -0,0 +1,186 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+)
+
+const testMain = `package main
+
+import (
+	"example.com/app/handler"
+	"github.com/labstack/echo/v4"
+)
+
+func main() {
+	app := echo.New()
+	exampleHandler := &handler.ExampleHandler{}
+	app.Static("/static", "assets")
+	app.GET("/example", exampleHandler.HandleExampleShow)
+	app.Start(":3000")
+}
+`
+
+func userEdit(t *testing.T) *routeEdit {
+	t.Helper()
+	routes, err := ParseRoutes("GET:/users,POST:/users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &routeEdit{Module: "example.com/app", Handler: "UserHandler", Var: "userHandler", Routes: routes}
+}
+
+func TestRouteEditApply(t *testing.T) {
+	e := userEdit(t)
+	out, err := e.apply([]byte(testMain))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `	app.GET("/example", exampleHandler.HandleExampleShow)
+	userHandler := &handler.UserHandler{}
+	app.GET("/users", userHandler.HandleGetUsers)
+	app.POST("/users", userHandler.HandlePostUsers)
+	app.Start(":3000")
+`
+	if !strings.Contains(string(out), want) {
+		t.Errorf("apply added the routes elsewhere:\n%s", out)
+	}
+
+	again, err := e.apply(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(out) {
+		t.Errorf("applying twice changed main again:\n%s", again)
+	}
+}
+
+func TestRouteEditAddsMissingRoutes(t *testing.T) {
+	src := strings.Replace(testMain, "\tapp.Start", "\tusers := &handler.UserHandler{}\n\tapp.GET(\"/users\", users.HandleGetUsers)\n\tapp.Start", 1)
+	out, err := userEdit(t).apply([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(out), "&handler.UserHandler{}"); n != 1 {
+		t.Errorf("main declares the handler %d times, want the existing variable reused", n)
+	}
+	if !strings.Contains(string(out), `app.POST("/users", users.HandlePostUsers)`) {
+		t.Errorf("the missing route was not registered on the existing variable:\n%s", out)
+	}
+}
+
+func TestRouteEditConflict(t *testing.T) {
+	src := strings.Replace(testMain, "\tapp.Start", "\tapp.GET(\"/users\", exampleHandler.HandleExampleShow)\n\tapp.Start", 1)
+	_, err := userEdit(t).apply([]byte(src))
+	if err == nil || !strings.Contains(err.Error(), "GET /users is already registered to exampleHandler.HandleExampleShow") {
+		t.Errorf("got error %v, want the route conflict", err)
+	}
+}
+
+func TestRouteEditAddsImports(t *testing.T) {
+	e := userEdit(t)
+	e.Fields = "Repo: repository.NewMemoryUserRepository()"
+	e.Imports = []string{"example.com/app/repository"}
+	out, err := e.apply([]byte(testMain))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"example.com/app/repository"`, "&handler.UserHandler{Repo: repository.NewMemoryUserRepository()}"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("main lacks %s:\n%s", want, out)
+		}
+	}
+}
+
+func TestRouteEditUnrecognized(t *testing.T) {
+	tests := []struct {
+		name, src, reason string
+	}{
+		{
+			name:   "no echo",
+			src:    "package main\n\nimport \"net/http\"\n\nfunc main() {\n\thttp.ListenAndServe(\":3000\", nil)\n}\n",
+			reason: "no func main using github.com/labstack/echo/v4",
+		},
+		{
+			name:   "no echo.New",
+			src:    strings.Replace(testMain, "app := echo.New()", "app := newApp()", 1),
+			reason: "func main does not create the app with echo.New()",
+		},
+		{
+			name:   "no Start",
+			src:    strings.Replace(testMain, "\tapp.Start(\":3000\")\n", "", 1),
+			reason: "func main does not call app.Start",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := userEdit(t).apply([]byte(tt.src))
+			if err == nil {
+				t.Fatal("apply succeeded, want a refusal")
+			}
+			for _, want := range []string{tt.reason, "register the routes by hand", `app.POST("/users", userHandler.HandlePostUsers)`} {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error lacks %q:\n%v", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestRouteEditGroup(t *testing.T) {
+	routes, err := ParseRoutes("GET:/settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := &routeEdit{
+		Module: "example.com/app", Handler: "PageHandler", Var: "pageHandler",
+		Group: "/admin", GroupVar: "adminGroup", Routes: routes,
+	}
+	out, err := e.apply([]byte(testMain))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `	pageHandler := &handler.PageHandler{}
+	adminGroup := app.Group("/admin")
+	adminGroup.GET("/settings", pageHandler.HandleGetSettings)
+	app.Start(":3000")
+`
+	if !strings.Contains(string(out), want) {
+		t.Errorf("apply did not add the group:\n%s", out)
+	}
+
+	// A second page under the same prefix reuses the group.
+	e.Routes, err = ParseRoutes("GET:/users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err = e.apply(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(out), `app.Group("/admin")`); n != 1 {
+		t.Errorf("main creates the group %d times, want 1:\n%s", n, out)
+	}
+	if !strings.Contains(string(out), `adminGroup.GET("/users", pageHandler.HandleGetUsers)`) {
+		t.Errorf("the route was not registered on the group:\n%s", out)
+	}
+}
+
+func TestRouteEditExistingGroup(t *testing.T) {
+	src := strings.Replace(testMain, "\tapp.Start", "\tadmin := app.Group(\"/admin\")\n\tapp.Start", 1)
+	routes, err := ParseRoutes("GET:/settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := &routeEdit{
+		Module: "example.com/app", Handler: "PageHandler", Var: "pageHandler",
+		Group: "/admin", GroupVar: "adminGroup", Routes: routes,
+	}
+	out, err := e.apply([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(out), "adminGroup") || !strings.Contains(string(out), `admin.GET("/settings", pageHandler.HandleGetSettings)`) {
+		t.Errorf("the route was not registered on the existing group:\n%s", out)
+	}
+}
@@ -0,0 +1,198 @@
+// Package codegen adds handlers, components, models, pages and features to a
+// project generated by Golosus.
+package codegen
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cagrigit-hub/golosus/scaffold"
+	"golang.org/x/mod/modfile"
+	"gopkg.in/yaml.v3"
+)
+
+// Workspace is an existing project on disk.
+type Workspace struct {
+	// Root is the directory holding go.mod.
+	Root string
+	// Module is the module path declared in go.mod.
+	Module string
+
+	// edits holds the content existing files had when a generator planned
+	// changes to them.
+	edits map[string][]byte
+}
+
+// Open finds the project dir belongs to by walking up to the closest go.mod.
+func Open(dir string) (*Workspace, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		src, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			module := modfile.ModulePath(src)
+			if module == "" {
+				return nil, fmt.Errorf("%s: no module line", filepath.Join(dir, "go.mod"))
+			}
+			return &Workspace{Root: dir, Module: module}, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, errors.New("no go.mod found, run this inside a Golosus project")
+		}
+		dir = parent
+	}
+}
+
+// backends maps the modules of the supported web frameworks to the backend
+// names of the built-in stack.
+var backends = map[string]string{
+	"github.com/labstack/echo/v4": "echo",
+	"github.com/go-chi/chi/v5":    "chi",
+	"github.com/gin-gonic/gin":    "gin",
+	"github.com/gofiber/fiber/v2": "fiber",
+}
+
+// Backend returns the web framework the project uses, as named by the
+// backend variable of the built-in stack: nethttp when go.mod requires none
+// of the others.
+func (w *Workspace) Backend() (string, error) {
+	src, err := w.read("go.mod")
+	if err != nil {
+		return "", err
+	}
+	f, err := modfile.ParseLax("go.mod", src, nil)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range f.Require {
+		if backend, ok := backends[r.Mod.Path]; ok {
+			return backend, nil
+		}
+	}
+	return "nethttp", nil
+}
+
+// requireEcho fails for projects that do not use Echo: generated handlers
+// and route registrations are written against it.
+func (w *Workspace) requireEcho() error {
+	backend, err := w.Backend()
+	if err != nil {
+		return err
+	}
+	if backend != "echo" {
+		return fmt.Errorf("handlers, resources and pages are generated for the echo backend only, this project uses %s", backend)
+	}
+	return nil
+}
+
+// Data returns the scaffold variables of the project: the ones recorded in
+// scaffold.AnswersFile when it was generated, with the module path of go.mod
+// and the backend it requires taking precedence.
+func (w *Workspace) Data() scaffold.Data {
+	data := scaffold.Data{
+		Name:   scaffold.ModuleName(w.Module),
+		Module: w.Module,
+	}
+	if parts := strings.Split(w.Module, "/"); len(parts) > 2 && parts[0] == "github.com" {
+		data.Author = parts[1]
+	}
+	if src, err := w.read(scaffold.AnswersFile); err == nil {
+		answers := map[string]string{}
+		if yaml.Unmarshal(src, &answers) == nil {
+			for name, value := range answers {
+				if name != "Module" && value != "" {
+					data.Set(name, value)
+				}
+			}
+		}
+	}
+	if backend, err := w.Backend(); err == nil {
+		data.Set("backend", backend)
+	}
+	return data
+}
+
+func (w *Workspace) project() *scaffold.Project {
+	data := w.Data()
+	data.Name = filepath.Base(w.Root)
+	return &scaffold.Project{Data: data}
+}
+
+func (w *Workspace) read(name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(w.Root, filepath.FromSlash(name)))
+}
+
+func (w *Workspace) exists(name string) bool {
+	_, err := os.Stat(filepath.Join(w.Root, filepath.FromSlash(name)))
+	return err == nil
+}
+
+// edit plans a change to the existing file name and returns its new content.
+func (w *Workspace) edit(name string, change func(src []byte) ([]byte, error)) (string, error) {
+	src, err := w.read(name)
+	if err != nil {
+		return "", err
+	}
+	out, err := change(src)
+	if err != nil {
+		return "", err
+	}
+	if w.edits == nil {
+		w.edits = map[string][]byte{}
+	}
+	w.edits[name] = src
+	return string(out), nil
+}
+
+// Edited reports whether name is an existing file a generator changed.
+func (w *Workspace) Edited(name string) bool {
+	_, ok := w.edits[name]
+	return ok
+}
+
+// Write adds the files of p to the workspace. Files that already exist with
+// the same content are left alone, which makes generators idempotent; a file
+// that exists with different content fails the whole write, unless it is one
+// a generator edited and it was not changed since.
+func (w *Workspace) Write(ctx context.Context, p *scaffold.Project) (*scaffold.Report, error) {
+	var conflicts, unchanged, write []string
+	for _, name := range p.Paths() {
+		content, _ := p.File(name)
+		existing, err := w.read(name)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			write = append(write, name)
+		case err != nil:
+			return nil, err
+		case bytes.Equal(existing, []byte(content)):
+			unchanged = append(unchanged, name)
+		case w.Edited(name) && bytes.Equal(existing, w.edits[name]):
+			write = append(write, name)
+		default:
+			conflicts = append(conflicts, name)
+		}
+	}
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("refusing to overwrite modified files: %s", strings.Join(conflicts, ", "))
+	}
+	report := &scaffold.Report{}
+	if len(write) > 0 {
+		var err error
+		if report, err = scaffold.Generate(ctx, p.Subset(write), w.Root, scaffold.PolicyForce); err != nil {
+			return nil, err
+		}
+	}
+	report.Unchanged = append(report.Unchanged, unchanged...)
+	return report, nil
+}
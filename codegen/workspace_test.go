@@ -0,0 +1,67 @@
+package codegen
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cagrigit-hub/golosus/scaffold"
+)
+
+func TestWorkspaceDataReadsAnswers(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod":             "module example.com/shop\n\ngo 1.22.0\n\nrequire github.com/go-chi/chi/v5 v5.0.12\n",
+		scaffold.AnswersFile: "Module: example.com/old\nName: shop\nbackend: echo\nbundler: esbuild\ncss: tailwind\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w, err := Open(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := w.Data()
+	for name, want := range map[string]string{
+		"Module":  "example.com/shop",
+		"Name":    "shop",
+		"backend": "chi",
+		"bundler": "esbuild",
+		"css":     "tailwind",
+	} {
+		if got := data.Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	p, err := w.Feature("typescript")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := p.File("typescript/build.js"); !ok {
+		t.Errorf("the typescript feature of an esbuild project has no build.js: %v", p.Paths())
+	}
+}
+
+// scaffolded generates the built-in project with vars into a temporary
+// directory and opens it.
+func scaffolded(t *testing.T, vars map[string]string) *Workspace {
+	t.Helper()
+	p, err := scaffold.New(scaffold.Data{Name: "app", Module: "example.com/app", Vars: vars})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(t.TempDir(), "app")
+	if _, err := scaffold.Generate(context.Background(), p, dir, scaffold.PolicyFail); err != nil {
+		t.Fatal(err)
+	}
+	w, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
@@ -0,0 +1,247 @@
+// Package doctor checks that the tools a Golosus project needs are installed
+// in the versions it needs.
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	StatusOK       Status = "ok"
+	StatusMissing  Status = "missing"
+	StatusOutdated Status = "outdated"
+	// StatusMismatch means the tool has to match a version exactly, like the
+	// templ CLI and the templ module, and does not.
+	StatusMismatch Status = "mismatch"
+	// StatusUnknown means the tool is installed but its version could not
+	// be read.
+	StatusUnknown Status = "unknown"
+)
+
+// Requirement is a tool a project needs.
+type Requirement struct {
+	Tool string
+	// Dir is searched for the tool before $PATH, e.g. the node_modules/.bin
+	// directory of a project.
+	Dir string
+	// Args print the version of the tool.
+	Args []string
+	// Minimum is the lowest acceptable version, empty for any version.
+	Minimum string
+	// Exact requires Minimum exactly.
+	Exact bool
+	// Optional tools only produce warnings.
+	Optional bool
+	// Fix tells how to install the tool in the right version.
+	Fix string
+}
+
+// Check is the result of checking one requirement.
+type Check struct {
+	Tool     string `json:"tool"`
+	Path     string `json:"path,omitempty"`
+	Version  string `json:"version,omitempty"`
+	Minimum  string `json:"minimum,omitempty"`
+	Exact    bool   `json:"exact,omitempty"`
+	Optional bool   `json:"optional,omitempty"`
+	Status   Status `json:"status"`
+	Fix      string `json:"fix,omitempty"`
+}
+
+// OK reports whether the check passed or only concerns an optional tool.
+func (c Check) OK() bool {
+	return c.Status == StatusOK || c.Optional
+}
+
+// Report is the outcome of all checks.
+type Report struct {
+	OK     bool    `json:"ok"`
+	Checks []Check `json:"checks"`
+}
+
+func (r *Report) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// Requirements returns the tools needed by a project with the given go.mod
+// and typescript/package.json contents. Either may be nil. The Dir of the
+// TypeScript compiler is relative to the project root.
+func Requirements(gomod, packageJSON []byte) ([]Requirement, error) {
+	goMin, templMin := "", ""
+	if gomod != nil {
+		f, err := modfile.ParseLax("go.mod", gomod, nil)
+		if err != nil {
+			return nil, err
+		}
+		if f.Go != nil {
+			goMin = f.Go.Version
+		}
+		for _, r := range f.Require {
+			if r.Mod.Path == "github.com/a-h/templ" {
+				templMin = strings.TrimPrefix(r.Mod.Version, "v")
+			}
+		}
+	}
+
+	nodeMin, tscMin := "", ""
+	if packageJSON != nil {
+		var pkg struct {
+			Engines         map[string]string `json:"engines"`
+			DevDependencies map[string]string `json:"devDependencies"`
+		}
+		if err := json.Unmarshal(packageJSON, &pkg); err != nil {
+			return nil, fmt.Errorf("package.json: %w", err)
+		}
+		nodeMin = strings.TrimSpace(strings.TrimPrefix(pkg.Engines["node"], ">="))
+		tscMin = strings.TrimLeft(pkg.DevDependencies["typescript"], "^~>= ")
+	}
+
+	templFix := "go install github.com/a-h/templ/cmd/templ@latest"
+	if templMin != "" {
+		templFix = "go install github.com/a-h/templ/cmd/templ@v" + templMin
+	}
+	return []Requirement{
+		{
+			Tool:    "go",
+			Args:    []string{"version"},
+			Minimum: goMin,
+			Fix:     fmt.Sprintf("install Go %s or newer from https://go.dev/dl/", orAny(goMin)),
+		},
+		{
+			Tool:    "node",
+			Args:    []string{"--version"},
+			Minimum: nodeMin,
+			Fix:     fmt.Sprintf("install Node.js %s or newer from https://nodejs.org/ or with `nvm install %s`", orAny(nodeMin), orAny(nodeMin)),
+		},
+		{
+			Tool: "npm",
+			Args: []string{"--version"},
+			Fix:  "npm ships with Node.js, reinstall Node.js or run `npm install -g npm`",
+		},
+		{
+			Tool:    "templ",
+			Args:    []string{"version"},
+			Minimum: templMin,
+			Exact:   templMin != "",
+			Fix:     templFix + " (the CLI has to match the templ version in go.mod)",
+		},
+		{
+			Tool:     "tsc",
+			Dir:      filepath.Join("typescript", "node_modules", ".bin"),
+			Args:     []string{"--version"},
+			Minimum:  tscMin,
+			Optional: true,
+			Fix:      "run `npm install` in typescript, it installs the TypeScript compiler of package.json",
+		},
+		{
+			Tool:     "air",
+			Args:     []string{"-v"},
+			Optional: true,
+			Fix:      "go install github.com/air-verse/air@latest (only needed for live reload)",
+		},
+		{
+			Tool:     "git",
+			Args:     []string{"--version"},
+			Optional: true,
+			Fix:      "install git from https://git-scm.com/ (used for git init after new)",
+		},
+	}, nil
+}
+
+func orAny(v string) string {
+	if v == "" {
+		return "latest"
+	}
+	return v
+}
+
+// Run checks every requirement against the tools found in their Dir or
+// $PATH.
+func Run(ctx context.Context, reqs []Requirement) *Report {
+	r := &Report{OK: true}
+	for _, req := range reqs {
+		c := check(ctx, req)
+		if !c.OK() {
+			r.OK = false
+		}
+		r.Checks = append(r.Checks, c)
+	}
+	return r
+}
+
+var versionRE = regexp.MustCompile(`v?(\d+\.\d+(?:\.\d+)?)`)
+
+func check(ctx context.Context, req Requirement) Check {
+	c := Check{
+		Tool:     req.Tool,
+		Minimum:  req.Minimum,
+		Exact:    req.Exact,
+		Optional: req.Optional,
+	}
+	path, err := lookPath(req)
+	if err != nil {
+		c.Status, c.Fix = StatusMissing, req.Fix
+		return c
+	}
+	c.Path = path
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	out, _ := exec.CommandContext(ctx, path, req.Args...).CombinedOutput()
+	m := versionRE.FindSubmatch(out)
+	if m == nil {
+		c.Status = StatusUnknown
+		if req.Minimum != "" {
+			c.Fix = req.Fix
+		}
+		return c
+	}
+	c.Version = string(m[1])
+
+	switch cmp := compare(c.Version, req.Minimum); {
+	case req.Minimum == "":
+		c.Status = StatusOK
+	case req.Exact && cmp != 0:
+		c.Status, c.Fix = StatusMismatch, req.Fix
+	case cmp < 0:
+		c.Status, c.Fix = StatusOutdated, req.Fix
+	default:
+		c.Status = StatusOK
+	}
+	return c
+}
+
+func lookPath(req Requirement) (string, error) {
+	if req.Dir != "" {
+		if path, err := exec.LookPath(filepath.Join(req.Dir, req.Tool)); err == nil {
+			return path, nil
+		}
+	}
+	return exec.LookPath(req.Tool)
+}
+
+// compare compares dotted versions like 1.22 and 1.22.0.
+func compare(a, b string) int {
+	return semver.Compare(canonical(a), canonical(b))
+}
+
+func canonical(v string) string {
+	v = "v" + strings.TrimPrefix(v, "v")
+	if strings.Count(v, ".") == 1 {
+		v += ".0"
+	}
+	return v
+}
@@ -0,0 +1,190 @@
+package doctor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestRequirements(t *testing.T) {
+	const gomod = "module example.com/app\n\ngo 1.22.0\n\nrequire (\n\tgithub.com/a-h/templ v0.2.543\n\tgithub.com/labstack/echo/v4 v4.11.4\n)\n"
+	const packageJSON = `{"engines": {"node": ">=18"}, "devDependencies": {"typescript": "^5.2.2"}}`
+
+	tests := []struct {
+		name               string
+		gomod, packageJSON string
+		// want maps tools to their Minimum.
+		want       map[string]string
+		templExact bool
+	}{
+		{
+			name:        "project",
+			gomod:       gomod,
+			packageJSON: packageJSON,
+			want:        map[string]string{"go": "1.22.0", "templ": "0.2.543", "node": "18", "tsc": "5.2.2", "npm": "", "air": "", "git": ""},
+			templExact:  true,
+		},
+		{
+			name:       "no package.json",
+			gomod:      gomod,
+			want:       map[string]string{"go": "1.22.0", "templ": "0.2.543", "node": "", "tsc": ""},
+			templExact: true,
+		},
+		{
+			name: "nothing",
+			want: map[string]string{"go": "", "templ": "", "node": "", "tsc": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gomod, packageJSON []byte
+			if tt.gomod != "" {
+				gomod = []byte(tt.gomod)
+			}
+			if tt.packageJSON != "" {
+				packageJSON = []byte(tt.packageJSON)
+			}
+			reqs, err := Requirements(gomod, packageJSON)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := map[string]Requirement{}
+			for _, r := range reqs {
+				got[r.Tool] = r
+			}
+			for tool, min := range tt.want {
+				r, ok := got[tool]
+				if !ok {
+					t.Errorf("no requirement for %s", tool)
+					continue
+				}
+				if r.Minimum != min {
+					t.Errorf("%s: Minimum = %q, want %q", tool, r.Minimum, min)
+				}
+			}
+			if got["templ"].Exact != tt.templExact {
+				t.Errorf("templ: Exact = %v, want %v", got["templ"].Exact, tt.templExact)
+			}
+			if !got["tsc"].Optional || got["tsc"].Dir == "" {
+				t.Errorf("tsc = %+v, want an optional tool looked up in node_modules/.bin", got["tsc"])
+			}
+		})
+	}
+}
+
+func TestRequirementsInvalid(t *testing.T) {
+	if _, err := Requirements([]byte("module"), nil); err == nil {
+		t.Error("broken go.mod: got no error")
+	}
+	if _, err := Requirements(nil, []byte("{")); err == nil {
+		t.Error("broken package.json: got no error")
+	}
+}
+
+func TestVersionRE(t *testing.T) {
+	tests := []struct {
+		out, want string
+	}{
+		{"go version go1.22.0 linux/amd64", "1.22.0"},
+		{"go version go1.22 darwin/arm64", "1.22"},
+		{"v20.11.0\n", "20.11.0"},
+		{"10.2.4\n", "10.2.4"},
+		{"v0.2.543\n", "0.2.543"},
+		{"Version 5.2.2\n", "5.2.2"},
+		{"git version 2.39.5", "2.39.5"},
+	}
+	for _, tt := range tests {
+		m := versionRE.FindStringSubmatch(tt.out)
+		if m == nil || m[1] != tt.want {
+			t.Errorf("versionRE in %q = %q, want %q", tt.out, m, tt.want)
+		}
+	}
+	if m := versionRE.FindStringSubmatch("command not found"); m != nil {
+		t.Errorf("versionRE matched %q in output without a version", m)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.22", "1.22.0", 0},
+		{"1.22.1", "1.22", 1},
+		{"1.21.9", "1.22.0", -1},
+		{"v0.2.543", "0.2.543", 0},
+		{"0.2.513", "0.2.543", -1},
+		{"20.11.0", "18", 1},
+	}
+	for _, tt := range tests {
+		if got := compare(tt.a, tt.b); got != tt.want {
+			t.Errorf("compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+	for v, want := range map[string]string{"1.22": "v1.22.0", "v1.22.0": "v1.22.0", "0.2.543": "v0.2.543", "18": "v18"} {
+		if got := canonical(v); got != want {
+			t.Errorf("canonical(%q) = %q, want %q", v, got, want)
+		}
+	}
+}
+
+// fakeTool installs a tool printing output into dir.
+func fakeTool(t *testing.T, dir, name, output string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake tools are shell scripts")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/sh\necho '" + output + "'\n"
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+	fakeTool(t, bin, "templ", "v0.2.513")
+	fakeTool(t, bin, "node", "v16.20.0")
+	fakeTool(t, bin, "odd", "no version here")
+
+	local := filepath.Join(t.TempDir(), "node_modules", ".bin")
+	fakeTool(t, local, "tsc", "Version 5.4.5")
+	fakeTool(t, bin, "tsc", "Version 4.9.5")
+
+	tests := []struct {
+		req     Requirement
+		status  Status
+		version string
+	}{
+		{Requirement{Tool: "templ", Args: []string{"version"}, Minimum: "0.2.543", Exact: true, Fix: "fix"}, StatusMismatch, "0.2.513"},
+		{Requirement{Tool: "templ", Args: []string{"version"}, Minimum: "0.2.513", Exact: true}, StatusOK, "0.2.513"},
+		{Requirement{Tool: "node", Minimum: "18", Fix: "fix"}, StatusOutdated, "16.20.0"},
+		{Requirement{Tool: "node"}, StatusOK, "16.20.0"},
+		{Requirement{Tool: "odd", Minimum: "1", Fix: "fix"}, StatusUnknown, ""},
+		{Requirement{Tool: "air", Fix: "fix"}, StatusMissing, ""},
+		{Requirement{Tool: "tsc", Dir: local, Minimum: "5.2.2"}, StatusOK, "5.4.5"},
+		{Requirement{Tool: "tsc", Minimum: "5.2.2", Fix: "fix"}, StatusOutdated, "4.9.5"},
+	}
+	for _, tt := range tests {
+		c := check(context.Background(), tt.req)
+		if c.Status != tt.status || c.Version != tt.version {
+			t.Errorf("check(%+v) = %s %q, want %s %q", tt.req, c.Status, c.Version, tt.status, tt.version)
+		}
+		if wantFix := tt.status != StatusOK; (c.Fix != "") != wantFix {
+			t.Errorf("check(%+v): Fix = %q", tt.req, c.Fix)
+		}
+	}
+
+	r := Run(context.Background(), []Requirement{
+		{Tool: "templ", Minimum: "0.2.543", Exact: true},
+		{Tool: "air", Optional: true},
+	})
+	if r.OK {
+		t.Errorf("Run reported OK with a templ mismatch")
+	}
+}
@@ -1,3 +1,9 @@
 module github.com/cagrigit-hub/golosus
 
 go 1.22.0
+
+require (
+	github.com/BurntSushi/toml v1.4.0
+	golang.org/x/mod v0.17.0
+	gopkg.in/yaml.v3 v3.0.1
+)
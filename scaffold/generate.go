@@ -0,0 +1,138 @@
+package scaffold
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Generate renders p into dir following policy. The project is first rendered
+// into a staging directory next to dir and only moved into place once every
+// folder and file has been written. On error or when ctx is cancelled the
+// staging directory is removed and dir is left as it was.
+func Generate(ctx context.Context, p *Project, dir string, policy Policy) (*Report, error) {
+	if policy == PolicyFail {
+		empty, err := isEmptyDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		if !empty {
+			return nil, fmt.Errorf("%s: %w", dir, ErrNotEmpty)
+		}
+	}
+
+	dir = filepath.Clean(dir)
+	if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		return nil, err
+	}
+	staging, err := os.MkdirTemp(filepath.Dir(dir), "."+filepath.Base(dir)+".golosus-*")
+	if err != nil {
+		return nil, err
+	}
+	staging = filepath.Clean(staging)
+	defer os.RemoveAll(staging)
+
+	w := &DiskWriter{Root: staging, Base: dir, Policy: policy}
+	if err := p.Render(&ctxWriter{ctx: ctx, w: w}); err != nil {
+		return &w.Report, err
+	}
+	if err := ctx.Err(); err != nil {
+		return &w.Report, err
+	}
+	if err := commit(staging, dir); err != nil {
+		return &w.Report, err
+	}
+	return &w.Report, nil
+}
+
+// ctxWriter stops rendering as soon as ctx is done.
+type ctxWriter struct {
+	ctx context.Context
+	w   Writer
+}
+
+func (w *ctxWriter) MkdirAll(dir string) error {
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+	return w.w.MkdirAll(dir)
+}
+
+func (w *ctxWriter) WriteFile(name string, data []byte) error {
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+	return w.w.WriteFile(name, data)
+}
+
+// commit moves the staged tree into dir. A missing dir is replaced by the
+// staging directory in a single rename. Otherwise files are moved one by one
+// and every replaced file is backed up, so a failure restores dir.
+func commit(staging, dir string) error {
+	if _, err := os.Lstat(dir); errors.Is(err, os.ErrNotExist) {
+		if err := os.Chmod(staging, 0o755); err != nil {
+			return err
+		}
+		return os.Rename(staging, dir)
+	}
+
+	backup, err := os.MkdirTemp(staging, ".backup-*")
+	if err != nil {
+		return err
+	}
+	var undo []func() error
+	rollback := func() {
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+	}
+
+	err = filepath.WalkDir(staging, func(src string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if src == backup {
+			return filepath.SkipDir
+		}
+		rel, err := filepath.Rel(staging, src)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, rel)
+
+		if d.IsDir() {
+			if _, err := os.Stat(target); err == nil {
+				return nil
+			}
+			if err := os.Mkdir(target, 0o755); err != nil {
+				return err
+			}
+			undo = append(undo, func() error { return os.Remove(target) })
+			return nil
+		}
+
+		if _, err := os.Lstat(target); err == nil {
+			saved := filepath.Join(backup, rel)
+			if err := os.MkdirAll(filepath.Dir(saved), 0o755); err != nil {
+				return err
+			}
+			if err := os.Rename(target, saved); err != nil {
+				return err
+			}
+			undo = append(undo, func() error { return os.Rename(saved, target) })
+		}
+		if err := os.Rename(src, target); err != nil {
+			return err
+		}
+		undo = append(undo, func() error { return os.Remove(target) })
+		return nil
+	})
+	if err != nil {
+		rollback()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package scaffold
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCommitNewDir(t *testing.T) {
+	parent := t.TempDir()
+	staging := filepath.Join(parent, ".staging")
+	writeFiles(t, staging, map[string]string{"a.txt": "a", "sub/b.txt": "b"})
+
+	dir := filepath.Join(parent, "project")
+	if err := commit(staging, dir); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, filepath.Join(dir, "sub", "b.txt")); got != "b" {
+		t.Errorf("sub/b.txt = %q, want %q", got, "b")
+	}
+	if _, err := os.Stat(staging); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("staging directory left behind")
+	}
+}
+
+func TestCommitRollback(t *testing.T) {
+	dir, staging := t.TempDir(), t.TempDir()
+	writeFiles(t, dir, map[string]string{"a.txt": "old a", "sub": "a file in the way"})
+	// The walk replaces a.txt, adds b.txt and then fails to move sub/c.txt
+	// below the file named sub.
+	writeFiles(t, staging, map[string]string{"a.txt": "new a", "b.txt": "new b", "sub/c.txt": "new c"})
+
+	if err := commit(staging, dir); err == nil {
+		t.Fatal("commit succeeded, want an error")
+	}
+	if got := readFile(t, filepath.Join(dir, "a.txt")); got != "old a" {
+		t.Errorf("a.txt = %q after rollback, want %q", got, "old a")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "b.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("b.txt was not removed by the rollback")
+	}
+	if got := readFile(t, filepath.Join(dir, "sub")); got != "a file in the way" {
+		t.Errorf("sub = %q after rollback, want %q", got, "a file in the way")
+	}
+}
+
+func TestGenerateCancelled(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "project")
+	writeFiles(t, dir, map[string]string{"a.txt": "old a"})
+
+	p := &Project{}
+	p.Add("a.txt", "new a")
+	p.Add("b.txt", "new b")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Generate(ctx, p, dir, PolicyForce); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Generate: got error %v, want %v", err, context.Canceled)
+	}
+
+	if got := readFile(t, filepath.Join(dir, "a.txt")); got != "old a" {
+		t.Errorf("a.txt = %q, want %q", got, "old a")
+	}
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("parent holds %d entries, want only the project; the staging directory was left behind", len(entries))
+	}
+}
@@ -0,0 +1,131 @@
+package scaffold
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// GoModOptions configure the go commands run by GoModTidy.
+type GoModOptions struct {
+	// Module is passed to go mod init when dir has no go.mod yet.
+	Module string
+	// GOPROXY and GOFLAGS override the environment when set, e.g. "off" and
+	// "-mod=mod" to resolve from the local module cache only.
+	GOPROXY string
+	GOFLAGS string
+	// Stdout and Stderr receive the output of the go commands.
+	Stdout, Stderr io.Writer
+}
+
+// ErrOffline reports that go mod tidy could not resolve the dependencies
+// and go.mod and go.sum were left as they were.
+var ErrOffline = errors.New("could not resolve dependencies, left go.mod and go.sum as they were")
+
+// GoModTidy runs go mod init, unless dir already has a go.mod, and go mod
+// tidy in dir. Tidy tolerates packages that do not exist yet, like views
+// before templ generate ran, but a direct requirement of the generated go.mod
+// that it drops could not be resolved. In that case, or when tidy fails, e.g.
+// because there is no network, go.mod and go.sum are restored to what they
+// were before, a go.sum tidy created is removed and the returned error wraps
+// ErrOffline.
+func GoModTidy(ctx context.Context, dir string, opts GoModOptions) error {
+	gomod := filepath.Join(dir, "go.mod")
+	generated, err := os.ReadFile(gomod)
+	if errors.Is(err, os.ErrNotExist) {
+		if opts.Module == "" {
+			return errors.New("go mod init: no module path")
+		}
+		if err := goCommand(ctx, dir, opts, "mod", "init", opts.Module); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	gosum := filepath.Join(dir, "go.sum")
+	sums, err := os.ReadFile(gosum)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	err = goCommand(ctx, dir, opts, "mod", "tidy", "-e")
+	if err == nil {
+		err = checkRequirements(gomod, generated)
+	}
+	if err != nil {
+		if generated != nil {
+			if err := os.WriteFile(gomod, generated, 0o644); err != nil {
+				return err
+			}
+		}
+		if sums != nil {
+			if err := os.WriteFile(gosum, sums, 0o644); err != nil {
+				return err
+			}
+		} else if err := os.Remove(gosum); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", ErrOffline, err)
+	}
+	return nil
+}
+
+// checkRequirements reports direct requirements of generated that the go.mod
+// at gomod lost.
+func checkRequirements(gomod string, generated []byte) error {
+	if generated == nil {
+		return nil
+	}
+	before, err := modfile.ParseLax("go.mod", generated, nil)
+	if err != nil {
+		return err
+	}
+	src, err := os.ReadFile(gomod)
+	if err != nil {
+		return err
+	}
+	after, err := modfile.ParseLax("go.mod", src, nil)
+	if err != nil {
+		return err
+	}
+	kept := map[string]bool{}
+	for _, r := range after.Require {
+		kept[r.Mod.Path] = true
+	}
+	var lost []string
+	for _, r := range before.Require {
+		if !r.Indirect && !kept[r.Mod.Path] {
+			lost = append(lost, r.Mod.Path)
+		}
+	}
+	if len(lost) > 0 {
+		return fmt.Errorf("go mod tidy dropped %s", strings.Join(lost, ", "))
+	}
+	return nil
+}
+
+func goCommand(ctx context.Context, dir string, opts GoModOptions, args ...string) error {
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = dir
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = opts.Stderr
+	cmd.Env = os.Environ()
+	if opts.GOPROXY != "" {
+		cmd.Env = append(cmd.Env, "GOPROXY="+opts.GOPROXY)
+	}
+	if opts.GOFLAGS != "" {
+		cmd.Env = append(cmd.Env, "GOFLAGS="+opts.GOFLAGS)
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("go %s %s: %w", args[0], args[1], err)
+	}
+	return nil
+}
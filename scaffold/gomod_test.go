@@ -0,0 +1,53 @@
+package scaffold
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestGoModTidyOfflineRestores(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+	const gomod = "module example.com/app\n\ngo 1.22.0\n\nrequire example.invalid/missing v1.0.0\n"
+	const main = "package main\n\nimport _ \"example.invalid/missing\"\n\nfunc main() {}\n"
+
+	tests := []struct {
+		name string
+		sums string
+	}{
+		{"existing go.sum", "example.invalid/other v1.0.0 h1:sentinel=\n"},
+		{"no go.sum", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			files := map[string]string{"go.mod": gomod, "main.go": main}
+			if tt.sums != "" {
+				files["go.sum"] = tt.sums
+			}
+			writeFiles(t, dir, files)
+			t.Setenv("GOFLAGS", "-mod=mod")
+			t.Setenv("GOPROXY", "off")
+
+			err := GoModTidy(context.Background(), dir, GoModOptions{})
+			if !errors.Is(err, ErrOffline) {
+				t.Fatalf("GoModTidy: got error %v, want %v", err, ErrOffline)
+			}
+			if got := readFile(t, filepath.Join(dir, "go.mod")); got != gomod {
+				t.Errorf("go.mod = %q, want it restored", got)
+			}
+			sums, err := os.ReadFile(filepath.Join(dir, "go.sum"))
+			switch {
+			case tt.sums == "" && !errors.Is(err, os.ErrNotExist):
+				t.Errorf("go.sum created by tidy was kept: %q", sums)
+			case tt.sums != "" && string(sums) != tt.sums:
+				t.Errorf("go.sum = %q, %v; want the existing one restored", sums, err)
+			}
+		})
+	}
+}
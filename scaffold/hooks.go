@@ -0,0 +1,280 @@
+package scaffold
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// DefaultHookTimeout bounds a hook that does not set its own timeout.
+const DefaultHookTimeout = 5 * time.Minute
+
+// Hook is a post-generation step declared in a manifest. It either runs one
+// of the built-in steps by name or a shell command. Every field may use
+// template actions and is rendered with the project data.
+type Hook struct {
+	// Name labels the hook in logs, it defaults to Step or Run.
+	Name string `yaml:"name" toml:"name"`
+	// Step is a built-in step: templ-generate, go-mod-tidy, npm-install,
+	// pnpm-install, bun-install, git-init or git-commit.
+	Step string `yaml:"step" toml:"step"`
+	// Run is a shell command, run with sh -c.
+	Run string `yaml:"run" toml:"run"`
+	// Dir is the directory to run in, relative to the project root.
+	Dir string `yaml:"dir" toml:"dir"`
+	// Timeout is a duration like 30s or 2m, DefaultHookTimeout when empty.
+	Timeout string `yaml:"timeout" toml:"timeout"`
+	// When is a template pipeline like FileMapping.When.
+	When string `yaml:"when" toml:"when"`
+}
+
+// Step is one post-generation step.
+type Step struct {
+	Name    string
+	Dir     string
+	Timeout time.Duration
+	Run     runFunc
+}
+
+// runFunc runs a step in dir and writes its output to out.
+type runFunc func(ctx context.Context, dir string, out io.Writer) error
+
+// StepOptions configure the built-in steps.
+type StepOptions struct {
+	GoMod GoModOptions
+	// Existed reports that the project directory had files before
+	// generation. Every step could change them, so all are skipped.
+	Existed bool
+	// AllowRun allows hooks that run shell commands. Without it they are
+	// skipped, so a fetched pack cannot run arbitrary commands unasked.
+	AllowRun bool
+}
+
+// ErrSkipped reports that a step decided not to run.
+var ErrSkipped = errors.New("skipped")
+
+// builtinSteps are the steps a Hook can refer to by name.
+var builtinSteps = map[string]func(opts StepOptions, data Data) runFunc{
+	"templ-generate": func(StepOptions, Data) runFunc {
+		return command("templ", "generate")
+	},
+	"go-mod-tidy": func(opts StepOptions, data Data) runFunc {
+		return func(ctx context.Context, dir string, out io.Writer) error {
+			gomod := opts.GoMod
+			if gomod.Module == "" {
+				gomod.Module = data.Module
+			}
+			gomod.Stdout, gomod.Stderr = out, out
+			return GoModTidy(ctx, dir, gomod)
+		}
+	},
+	"npm-install": func(StepOptions, Data) runFunc {
+		return command("npm", "install")
+	},
+	"pnpm-install": func(StepOptions, Data) runFunc {
+		return command("pnpm", "install")
+	},
+	"bun-install": func(StepOptions, Data) runFunc {
+		return command("bun", "install")
+	},
+	"git-init": func(opts StepOptions, _ Data) runFunc {
+		return func(ctx context.Context, dir string, out io.Writer) error {
+			if top, ok := workTree(ctx, dir); ok {
+				return fmt.Errorf("%w: the project is inside the git work tree %s", ErrSkipped, top)
+			}
+			return command("git", "init", "--quiet")(ctx, dir, out)
+		}
+	},
+	"git-commit": func(opts StepOptions, _ Data) runFunc {
+		return func(ctx context.Context, dir string, out io.Writer) error {
+			if top, ok := workTree(ctx, dir); ok && !sameDir(top, dir) {
+				return fmt.Errorf("%w: the project is inside the git work tree %s", ErrSkipped, top)
+			}
+			if err := command("git", "add", "--all")(ctx, dir, out); err != nil {
+				return err
+			}
+			return command("git", "commit", "--quiet", "--message", "Initial commit from Golosus")(ctx, dir, out)
+		}
+	},
+}
+
+// workTree returns the top level directory of the git work tree dir is in.
+func workTree(ctx context.Context, dir string) (string, bool) {
+	if inside, err := git(ctx, dir, "rev-parse", "--is-inside-work-tree"); err != nil || inside != "true" {
+		return "", false
+	}
+	top, err := git(ctx, dir, "rev-parse", "--show-toplevel")
+	return top, err == nil
+}
+
+// sameDir reports whether a and b name the same directory.
+func sameDir(a, b string) bool {
+	ia, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	ib, err := os.Stat(b)
+	return err == nil && os.SameFile(ia, ib)
+}
+
+func command(name string, args ...string) runFunc {
+	return func(ctx context.Context, dir string, out io.Writer) error {
+		if _, err := exec.LookPath(name); err != nil {
+			return fmt.Errorf("%s is not installed or not in PATH", name)
+		}
+		cmd := exec.CommandContext(ctx, name, args...)
+		cmd.Dir = dir
+		cmd.Stdout, cmd.Stderr = out, out
+		return cmd.Run()
+	}
+}
+
+func shell(script string) runFunc {
+	if runtime.GOOS == "windows" {
+		return command("cmd", "/C", script)
+	}
+	return command("sh", "-c", script)
+}
+
+func notAllowed(context.Context, string, io.Writer) error {
+	return fmt.Errorf("%w: shell commands are not allowed", ErrSkipped)
+}
+
+func existed(context.Context, string, io.Writer) error {
+	return fmt.Errorf("%w: the project directory was not empty", ErrSkipped)
+}
+
+// Steps renders the hooks of m with data into the steps to run after
+// generating. Hooks whose When is false are left out, Run hooks are skipped
+// unless opts.AllowRun is set and every step is skipped when opts.Existed is
+// set.
+func (m *Manifest) Steps(data Data, opts StepOptions) ([]Step, error) {
+	var steps []Step
+	for i, h := range m.Hooks {
+		if h.When != "" {
+			ok, err := evalCondition(h.When, data)
+			if err != nil {
+				return nil, fmt.Errorf("hook %d: when: %w", i+1, err)
+			}
+			if !ok {
+				continue
+			}
+		}
+
+		var fields [5]string
+		for j, src := range []string{h.Name, h.Step, h.Run, h.Dir, h.Timeout} {
+			value, err := render(fmt.Sprintf("hook %d", i+1), src, data)
+			if err != nil {
+				return nil, err
+			}
+			fields[j] = value
+		}
+		name, step, run, dir, timeout := fields[0], fields[1], fields[2], fields[3], fields[4]
+
+		s := Step{Name: name, Dir: dir, Timeout: DefaultHookTimeout}
+		switch {
+		case step != "":
+			builtin, ok := builtinSteps[step]
+			if !ok {
+				return nil, fmt.Errorf("hook %d: unknown step %q", i+1, step)
+			}
+			s.Run = builtin(opts, data)
+			if s.Name == "" {
+				s.Name = step
+			}
+		default:
+			s.Run = shell(run)
+			if !opts.AllowRun {
+				s.Run = notAllowed
+			}
+			if s.Name == "" {
+				s.Name = run
+			}
+		}
+		if opts.Existed {
+			s.Run = existed
+		}
+		if timeout != "" {
+			d, err := time.ParseDuration(timeout)
+			if err != nil {
+				return nil, fmt.Errorf("hook %s: %w", s.Name, err)
+			}
+			s.Timeout = d
+		}
+		steps = append(steps, s)
+	}
+	return steps, nil
+}
+
+// StepResult is the outcome of one step.
+type StepResult struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+}
+
+// RunSteps runs steps one after another in the project at root. Their output
+// is streamed to out with every line prefixed by the step name. A failing
+// step does not stop the steps after it; every outcome is returned.
+func RunSteps(ctx context.Context, root string, steps []Step, out io.Writer) []StepResult {
+	results := make([]StepResult, 0, len(steps))
+	for _, s := range steps {
+		if err := ctx.Err(); err != nil {
+			results = append(results, StepResult{Name: s.Name, Err: err})
+			continue
+		}
+		stepCtx, cancel := context.WithTimeout(ctx, s.Timeout)
+		w := &prefixWriter{w: out, prefix: "[" + s.Name + "] "}
+		start := time.Now()
+		err := s.Run(stepCtx, filepath.Join(root, filepath.FromSlash(s.Dir)), w)
+		w.Flush()
+		if errors.Is(stepCtx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("timed out after %s", s.Timeout)
+		}
+		cancel()
+		results = append(results, StepResult{Name: s.Name, Duration: time.Since(start), Err: err})
+	}
+	return results
+}
+
+// prefixWriter prefixes every line written to w.
+type prefixWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		if _, err := fmt.Fprintf(p.w, "%s%s\n", p.prefix, p.buf[:i]); err != nil {
+			return 0, err
+		}
+		p.buf = p.buf[i+1:]
+	}
+}
+
+// Flush writes a trailing line without newline.
+func (p *prefixWriter) Flush() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.buf) > 0 {
+		fmt.Fprintf(p.w, "%s%s\n", p.prefix, p.buf)
+		p.buf = nil
+	}
+}
@@ -0,0 +1,172 @@
+package scaffold
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// gitSteps runs git-init and git-commit in dir.
+func gitSteps(t *testing.T, dir string, opts StepOptions) []StepResult {
+	t.Helper()
+	m := &Manifest{Name: "test", Hooks: []Hook{{Step: "git-init"}, {Step: "git-commit"}}}
+	steps, err := m.Steps(Data{}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return RunSteps(context.Background(), dir, steps, io.Discard)
+}
+
+func gitEnv(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	for _, kv := range [][2]string{
+		{"GIT_AUTHOR_NAME", "test"}, {"GIT_AUTHOR_EMAIL", "test@example.com"},
+		{"GIT_COMMITTER_NAME", "test"}, {"GIT_COMMITTER_EMAIL", "test@example.com"},
+		{"GIT_CONFIG_GLOBAL", os.DevNull}, {"GIT_CONFIG_NOSYSTEM", "1"},
+	} {
+		t.Setenv(kv[0], kv[1])
+	}
+}
+
+func TestGitStepsCommitProject(t *testing.T) {
+	gitEnv(t)
+	dir := filepath.Join(t.TempDir(), "app")
+	writeFiles(t, dir, map[string]string{
+		".gitignore":              "node_modules/\n",
+		"main.go":                 "package main\n",
+		"go.sum":                  "written by go mod tidy",
+		"sub/a.txt":               "a",
+		"node_modules/x/index.js": "ignored",
+	})
+
+	results := gitSteps(t, dir, StepOptions{})
+	for _, r := range results {
+		if r.Err != nil {
+			t.Fatalf("%s: %v", r.Name, r.Err)
+		}
+	}
+	out, err := git(context.Background(), dir, "ls-files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Fields(out), []string{".gitignore", "go.sum", "main.go", "sub/a.txt"}; !slices.Equal(got, want) {
+		t.Errorf("committed %q, want %q", got, want)
+	}
+}
+
+func TestGitStepsSkipped(t *testing.T) {
+	gitEnv(t)
+	tests := []struct {
+		name string
+		// setup prepares the parent of the project directory and returns the
+		// step options.
+		setup func(t *testing.T, parent, dir string) StepOptions
+	}{
+		{
+			name: "inside a work tree",
+			setup: func(t *testing.T, parent, dir string) StepOptions {
+				if _, err := git(context.Background(), parent, "init", "--quiet"); err != nil {
+					t.Fatal(err)
+				}
+				writeFiles(t, parent, map[string]string{"wip.txt": "uncommitted"})
+				return StepOptions{}
+			},
+		},
+		{
+			name: "existing directory",
+			setup: func(t *testing.T, parent, dir string) StepOptions {
+				writeFiles(t, dir, map[string]string{"wip.txt": "uncommitted"})
+				return StepOptions{Existed: true}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := t.TempDir()
+			dir := filepath.Join(parent, "app")
+			opts := tt.setup(t, parent, dir)
+			writeFiles(t, dir, map[string]string{"main.go": "package main\n"})
+
+			for _, r := range gitSteps(t, dir, opts) {
+				if !errors.Is(r.Err, ErrSkipped) {
+					t.Errorf("%s: got error %v, want %v", r.Name, r.Err, ErrSkipped)
+				}
+			}
+			if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+				t.Errorf("a nested repository was created")
+			}
+			if out, _ := git(context.Background(), parent, "log", "--oneline"); out != "" {
+				t.Errorf("the enclosing repository got commits: %s", out)
+			}
+		})
+	}
+}
+
+func TestRunHooksNeedAllowRun(t *testing.T) {
+	m := &Manifest{Name: "test", Hooks: []Hook{{Run: "echo ran > ran.txt"}}}
+	for _, allow := range []bool{false, true} {
+		dir := t.TempDir()
+		steps, err := m.Steps(Data{}, StepOptions{AllowRun: allow})
+		if err != nil {
+			t.Fatal(err)
+		}
+		results := RunSteps(context.Background(), dir, steps, io.Discard)
+		_, statErr := os.Stat(filepath.Join(dir, "ran.txt"))
+		switch {
+		case !allow && !errors.Is(results[0].Err, ErrSkipped):
+			t.Errorf("AllowRun false: got error %v, want %v", results[0].Err, ErrSkipped)
+		case !allow && statErr == nil:
+			t.Errorf("AllowRun false: the hook ran")
+		case allow && (results[0].Err != nil || statErr != nil):
+			t.Errorf("AllowRun true: got error %v, ran.txt: %v", results[0].Err, statErr)
+		}
+	}
+}
+
+func TestStepsSkippedAfterMerge(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+	const gomod = "module example.com/mine\n\ngo 1.22.0\n"
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"go.mod": gomod, "main.go": "package main\n\nfunc main() {}\n"})
+
+	p := &Project{Data: Data{Module: "example.com/app"}}
+	p.Add("go.mod", "module example.com/app\n\ngo 1.22.0\n")
+	p.Add("README.md", "# app\n")
+	report, err := Generate(context.Background(), p, dir, PolicyMerge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(report.Conflicts, []string{"go.mod"}) {
+		t.Fatalf("conflicts = %q, want go.mod", report.Conflicts)
+	}
+
+	m := &Manifest{Name: "test", Hooks: []Hook{{Step: "go-mod-tidy"}, {Step: "git-init"}, {Run: "echo ran > ran.txt"}}}
+	steps, err := m.Steps(p.Data, StepOptions{Existed: true, AllowRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range RunSteps(context.Background(), dir, steps, io.Discard) {
+		if !errors.Is(r.Err, ErrSkipped) {
+			t.Errorf("%s: got error %v, want %v", r.Name, r.Err, ErrSkipped)
+		}
+	}
+	if got := readFile(t, filepath.Join(dir, "go.mod")); got != gomod {
+		t.Errorf("go.mod = %q, want the user's go.mod", got)
+	}
+	for _, name := range []string{"go.sum", ".git", "ran.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			t.Errorf("a step created %s", name)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+package scaffold
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestNames are the files at the root of a template pack that describe
+// it, in lookup order. They are never rendered into the generated project.
+var ManifestNames = []string{"golosus.yaml", "golosus.toml"}
+
+// Manifest describes a template pack: the variables it needs, the directories
+// it creates and the files it renders.
+type Manifest struct {
+	Name        string `yaml:"name" toml:"name"`
+	Description string `yaml:"description" toml:"description"`
+	Version     string `yaml:"version" toml:"version"`
+
+	Variables   []Variable    `yaml:"variables" toml:"variables"`
+	Constraints []Constraint  `yaml:"constraints" toml:"constraints"`
+	Directories []string      `yaml:"directories" toml:"directories"`
+	Files       []FileMapping `yaml:"files" toml:"files"`
+
+	// Hooks run in order after the project was written.
+	Hooks []Hook `yaml:"hooks" toml:"hooks"`
+
+	// Features are parts of the pack that can be added to an existing
+	// project with `golosus add`.
+	Features map[string]Feature `yaml:"features" toml:"features"`
+}
+
+// Feature names the generated paths that make up an optional part of a pack.
+type Feature struct {
+	Description string   `yaml:"description" toml:"description"`
+	Paths       []string `yaml:"paths" toml:"paths"`
+}
+
+// Variable declares a template variable. Name, Module and Author refer to the
+// Data fields of the same name, anything else to an entry of Data.Vars.
+type Variable struct {
+	Name        string `yaml:"name" toml:"name"`
+	Description string `yaml:"description" toml:"description"`
+	Default     string `yaml:"default" toml:"default"`
+	Required    bool   `yaml:"required" toml:"required"`
+	// Pattern is a regular expression the whole value has to match.
+	Pattern string `yaml:"pattern" toml:"pattern"`
+	// Choices, when set, are the only values allowed.
+	Choices []string `yaml:"choices" toml:"choices"`
+}
+
+// Constraint rules out combinations of variable values. Require is a template
+// pipeline like FileMapping.When that has to hold once every variable is
+// resolved; Message explains the problem when it does not.
+type Constraint struct {
+	Require string `yaml:"require" toml:"require"`
+	Message string `yaml:"message" toml:"message"`
+}
+
+// FileMapping renders the pack file Src to Dest in the project. Dest defaults
+// to Src without the template extension and may use template actions itself.
+// When is a template pipeline, e.g. `eq .Vars.css "tailwind"`; the file is
+// skipped unless it evaluates to a non-empty, non-zero value.
+type FileMapping struct {
+	Src  string `yaml:"src" toml:"src"`
+	Dest string `yaml:"dest" toml:"dest"`
+	When string `yaml:"when" toml:"when"`
+}
+
+// LoadManifest reads and validates the manifest at the root of fsys.
+func LoadManifest(fsys fs.FS) (*Manifest, error) {
+	for _, name := range ManifestNames {
+		src, err := fs.ReadFile(fsys, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		m := &Manifest{}
+		if path.Ext(name) == ".toml" {
+			err = toml.Unmarshal(src, m)
+		} else {
+			err = yaml.Unmarshal(src, m)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if err := m.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("no %s found", strings.Join(ManifestNames, " or "))
+}
+
+func isManifest(name string) bool {
+	for _, m := range ManifestNames {
+		if name == m {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Manifest) Validate() error {
+	var errs []error
+	if m.Name == "" {
+		errs = append(errs, errors.New("name is required"))
+	}
+
+	seen := map[string]bool{}
+	for _, v := range m.Variables {
+		switch {
+		case v.Name == "":
+			errs = append(errs, errors.New("variable without a name"))
+		case seen[v.Name]:
+			errs = append(errs, fmt.Errorf("variable %s: declared twice", v.Name))
+		}
+		seen[v.Name] = true
+		if v.Pattern != "" {
+			if _, err := regexp.Compile(v.Pattern); err != nil {
+				errs = append(errs, fmt.Errorf("variable %s: %w", v.Name, err))
+			}
+		}
+		if len(v.Choices) > 0 && v.Default != "" && !strings.Contains(v.Default, "{{") && !slices.Contains(v.Choices, v.Default) {
+			errs = append(errs, fmt.Errorf("variable %s: default %q is not one of %s", v.Name, v.Default, strings.Join(v.Choices, ", ")))
+		}
+	}
+
+	for i, c := range m.Constraints {
+		if c.Require == "" || c.Message == "" {
+			errs = append(errs, fmt.Errorf("constraint %d: needs require and message", i+1))
+			continue
+		}
+		if _, err := parseCondition(c.Require); err != nil {
+			errs = append(errs, fmt.Errorf("constraint %d: require: %w", i+1, err))
+		}
+	}
+
+	for _, dir := range m.Directories {
+		if !fs.ValidPath(dir) {
+			errs = append(errs, fmt.Errorf("directory %q: invalid path", dir))
+		}
+	}
+
+	for _, f := range m.Files {
+		if !fs.ValidPath(f.Src) || f.Src == "." {
+			errs = append(errs, fmt.Errorf("file %q: invalid src", f.Src))
+		}
+		if f.When != "" {
+			if _, err := parseCondition(f.When); err != nil {
+				errs = append(errs, fmt.Errorf("file %s: when: %w", f.Src, err))
+			}
+		}
+	}
+	for i, h := range m.Hooks {
+		switch {
+		case h.Step == "" && h.Run == "":
+			errs = append(errs, fmt.Errorf("hook %d: needs step or run", i+1))
+		case h.Step != "" && h.Run != "":
+			errs = append(errs, fmt.Errorf("hook %d: step and run are exclusive", i+1))
+		case h.Step != "" && !strings.Contains(h.Step, "{{"):
+			if _, ok := builtinSteps[h.Step]; !ok {
+				errs = append(errs, fmt.Errorf("hook %d: unknown step %q", i+1, h.Step))
+			}
+		}
+		if h.When != "" {
+			if _, err := parseCondition(h.When); err != nil {
+				errs = append(errs, fmt.Errorf("hook %d: when: %w", i+1, err))
+			}
+		}
+	}
+	for name, f := range m.Features {
+		if len(f.Paths) == 0 {
+			errs = append(errs, fmt.Errorf("feature %s: no paths", name))
+		}
+		for _, p := range f.Paths {
+			if !fs.ValidPath(p) {
+				errs = append(errs, fmt.Errorf("feature %s: invalid path %q", name, p))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Resolve fills in variable defaults and checks every declared variable
+// against data, and then the constraints against the resolved values. All
+// problems are reported at once.
+func (m *Manifest) Resolve(data Data) (Data, error) {
+	vars := map[string]string{}
+	for k, v := range data.Vars {
+		vars[k] = v
+	}
+	data.Vars = vars
+
+	if data.Name != "" {
+		names, err := NormalizeName(data.Name)
+		if err != nil {
+			return data, err
+		}
+		data.Name = names.Dir
+		if data.Title == "" {
+			data.Title = names.Title
+		}
+		if data.NPMName == "" {
+			data.NPMName = names.NPM
+		}
+	}
+
+	var errs []error
+	for _, v := range m.Variables {
+		value := data.Get(v.Name)
+		if value == "" {
+			def, err := v.DefaultFor(data)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			value = def
+			data.Set(v.Name, value)
+		}
+		if err := v.Check(value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if data.Module != "" && !declared(m.Variables, "Module") {
+		if err := CheckModule(data.Module); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return data, errors.Join(errs...)
+	}
+	broken, err := m.Broken(data)
+	if err != nil {
+		return data, err
+	}
+	for _, c := range broken {
+		errs = append(errs, errors.New(c.Message))
+	}
+	return data, errors.Join(errs...)
+}
+
+// Broken returns the constraints of m that data does not satisfy.
+func (m *Manifest) Broken(data Data) ([]Constraint, error) {
+	var broken []Constraint
+	for _, c := range m.Constraints {
+		ok, err := evalCondition(c.Require, data)
+		if err != nil {
+			return nil, fmt.Errorf("constraint %q: %w", c.Require, err)
+		}
+		if !ok {
+			broken = append(broken, c)
+		}
+	}
+	return broken, nil
+}
+
+// AnswersFile records the variable values a project was generated with, in
+// the format of -answers files, so code and features added to the project
+// later are rendered for the same stack.
+const AnswersFile = ".golosus.yaml"
+
+// Answers returns the value of every variable of m in data.
+func (m *Manifest) Answers(data Data) map[string]string {
+	answers := map[string]string{}
+	for _, v := range m.Variables {
+		answers[v.Name] = data.Get(v.Name)
+	}
+	return answers
+}
+
+// answersFile renders the AnswersFile of a project generated with data.
+func (m *Manifest) answersFile(data Data) (string, error) {
+	src, err := yaml.Marshal(m.Answers(data))
+	if err != nil {
+		return "", err
+	}
+	return "# The variables this project was generated with, read by golosus add\n# and golosus generate.\n" + string(src), nil
+}
+
+func declared(vars []Variable, name string) bool {
+	for _, v := range vars {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultFor renders the default of v, which may refer to other variables,
+// with data.
+func (v *Variable) DefaultFor(data Data) (string, error) {
+	if v.Default == "" {
+		return "", nil
+	}
+	value, err := render(v.Name+" default", v.Default, data)
+	if err != nil {
+		return "", fmt.Errorf("variable %s: %w", v.Name, err)
+	}
+	return value, nil
+}
+
+// Check reports whether value is acceptable for v.
+func (v *Variable) Check(value string) error {
+	if value == "" {
+		if v.Required {
+			return fmt.Errorf("variable %s is required", v.Name)
+		}
+		return nil
+	}
+	if len(v.Choices) > 0 && !slices.Contains(v.Choices, value) {
+		return fmt.Errorf("variable %s: %q is not one of %s", v.Name, value, strings.Join(v.Choices, ", "))
+	}
+	if v.Pattern != "" && !regexp.MustCompile("^(?:"+v.Pattern+")$").MatchString(value) {
+		return fmt.Errorf("variable %s: %q does not match %s", v.Name, value, v.Pattern)
+	}
+	switch v.Name {
+	case "Name":
+		_, err := NormalizeName(value)
+		return err
+	case "Module":
+		return CheckModule(value)
+	}
+	return nil
+}
+
+// Render renders the files of the pack in fsys that m selects for data and
+// returns them keyed by output path. Without file mappings every file of the
+// pack is rendered.
+func (m *Manifest) Render(fsys fs.FS, data Data) (map[string]string, error) {
+	if len(m.Files) == 0 {
+		return renderFS(fsys, data)
+	}
+
+	out := map[string]string{}
+	for _, f := range m.Files {
+		if f.When != "" {
+			ok, err := evalCondition(f.When, data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: when: %w", f.Src, err)
+			}
+			if !ok {
+				continue
+			}
+		}
+
+		dest := strings.TrimSuffix(f.Src, TemplateExt)
+		if f.Dest != "" {
+			var err error
+			if dest, err = render(f.Src+" dest", f.Dest, data); err != nil {
+				return nil, err
+			}
+		}
+		if !fs.ValidPath(dest) || dest == "." {
+			return nil, fmt.Errorf("%s: invalid dest %q", f.Src, dest)
+		}
+
+		content, err := renderFile(fsys, f.Src, data)
+		if err != nil {
+			return nil, err
+		}
+		out[dest] = content
+	}
+	return out, nil
+}
+
+func parseCondition(when string) (*template.Template, error) {
+	return template.New("when").Option("missingkey=zero").Parse("{{if " + when + "}}true{{end}}")
+}
+
+func evalCondition(when string, data Data) (bool, error) {
+	tmpl, err := parseCondition(when)
+	if err != nil {
+		return false, err
+	}
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return false, err
+	}
+	return buf.String() == "true", nil
+}
@@ -0,0 +1,249 @@
+package scaffold
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestResolveConstraints(t *testing.T) {
+	m, err := LoadManifest(Templates())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		vars    map[string]string
+		wantErr string
+		wantCSS string
+	}{
+		{vars: map[string]string{}, wantCSS: "cdn"},
+		{vars: map[string]string{"assets": "vendor"}, wantCSS: "tailwind"},
+		{vars: map[string]string{"css": "tailwind"}, wantCSS: "tailwind"},
+		{vars: map[string]string{"assets": "vendor", "css": "cdn"}, wantErr: "assets vendor loads nothing from third-party origins"},
+	}
+	for _, tt := range tests {
+		data, err := m.Resolve(Data{Name: "app", Vars: tt.vars})
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Resolve(%v): got error %v, want %q", tt.vars, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Resolve(%v): %v", tt.vars, err)
+			continue
+		}
+		if got := data.Vars["css"]; got != tt.wantCSS {
+			t.Errorf("Resolve(%v): css = %q, want %q", tt.vars, got, tt.wantCSS)
+		}
+	}
+}
+
+func TestValidateConstraints(t *testing.T) {
+	m := &Manifest{Name: "test", Constraints: []Constraint{
+		{Require: "eq .Vars.a"},
+		{Require: "eq .Vars.a (", Message: "broken"},
+	}}
+	err := m.Validate()
+	for _, want := range []string{"constraint 1: needs require and message", "constraint 2: require:"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate: got error %v, want one containing %q", err, want)
+		}
+	}
+}
+
+const tomlManifest = `
+name = "toml-pack"
+description = "a pack described in TOML"
+
+[[variables]]
+name = "Name"
+required = true
+
+[[variables]]
+name = "db"
+choices = ["none", "postgres"]
+default = "none"
+
+[[variables]]
+name = "port"
+pattern = "[0-9]+"
+default = "8080"
+
+[[files]]
+src = "main.go.tmpl"
+
+[[files]]
+src = "db/db.go.tmpl"
+when = 'eq .Vars.db "postgres"'
+
+[[files]]
+src = "config.tmpl"
+dest = "config/{{.Name}}.env"
+
+[[hooks]]
+step = "go-mod-tidy"
+timeout = "1m"
+`
+
+func TestLoadManifestTOML(t *testing.T) {
+	pack := fstest.MapFS{"golosus.toml": {Data: []byte(tomlManifest)}}
+	m, err := LoadManifest(pack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Name != "toml-pack" || len(m.Variables) != 3 || len(m.Files) != 3 || len(m.Hooks) != 1 {
+		t.Fatalf("LoadManifest = %+v", m)
+	}
+	if v := m.Variables[1]; v.Name != "db" || v.Default != "none" || !slices.Equal(v.Choices, []string{"none", "postgres"}) {
+		t.Errorf("variable db = %+v", v)
+	}
+	if f := m.Files[1]; f.When != `eq .Vars.db "postgres"` {
+		t.Errorf("file db = %+v", f)
+	}
+
+	pack["golosus.yaml"] = &fstest.MapFile{Data: []byte("name: yaml-pack\n")}
+	if m, err := LoadManifest(pack); err != nil || m.Name != "yaml-pack" {
+		t.Errorf("with golosus.yaml and golosus.toml: got %v, %v; want the YAML manifest", m, err)
+	}
+
+	if _, err := LoadManifest(fstest.MapFS{}); err == nil || !strings.Contains(err.Error(), "no golosus.yaml or golosus.toml found") {
+		t.Errorf("without a manifest: got error %v", err)
+	}
+	broken := fstest.MapFS{"golosus.toml": {Data: []byte("name = \n")}}
+	if _, err := LoadManifest(broken); err == nil || !strings.Contains(err.Error(), "golosus.toml:") {
+		t.Errorf("broken TOML: got error %v", err)
+	}
+}
+
+func TestRenderWhen(t *testing.T) {
+	pack := fstest.MapFS{
+		"golosus.toml":  {Data: []byte(tomlManifest)},
+		"main.go.tmpl":  {Data: []byte("package main // {{.Name}} on {{.Vars.port}}\n")},
+		"db/db.go.tmpl": {Data: []byte("package db // {{.Vars.db}}\n")},
+		"config.tmpl":   {Data: []byte("DB={{.Vars.db}}\n")},
+		"unmapped.txt":  {Data: []byte("not in files")},
+	}
+	tests := []struct {
+		vars map[string]string
+		want map[string]string
+	}{
+		{
+			vars: map[string]string{},
+			want: map[string]string{
+				"main.go":        "package main // app on 8080\n",
+				"config/app.env": "DB=none\n",
+				AnswersFile:      "",
+			},
+		},
+		{
+			vars: map[string]string{"db": "postgres", "port": "9000"},
+			want: map[string]string{
+				"main.go":        "package main // app on 9000\n",
+				"db/db.go":       "package db // postgres\n",
+				"config/app.env": "DB=postgres\n",
+				AnswersFile:      "",
+			},
+		},
+	}
+	for _, tt := range tests {
+		p, err := NewFromPack(pack, Data{Name: "app", Vars: tt.vars})
+		if err != nil {
+			t.Fatalf("NewFromPack(%v): %v", tt.vars, err)
+		}
+		got := p.Paths()
+		var want []string
+		for name := range tt.want {
+			want = append(want, name)
+		}
+		slices.Sort(got)
+		slices.Sort(want)
+		if !slices.Equal(got, want) {
+			t.Errorf("NewFromPack(%v) rendered %q, want %q", tt.vars, got, want)
+		}
+		for name, content := range tt.want {
+			if got, _ := p.File(name); content != "" && got != content {
+				t.Errorf("NewFromPack(%v): %s = %q, want %q", tt.vars, name, got, content)
+			}
+		}
+	}
+}
+
+func TestVariableCheck(t *testing.T) {
+	tests := []struct {
+		v       Variable
+		value   string
+		wantErr string
+	}{
+		{Variable{Name: "port", Pattern: "[0-9]+"}, "8080", ""},
+		{Variable{Name: "port", Pattern: "[0-9]+"}, "80a", `variable port: "80a" does not match [0-9]+`},
+		{Variable{Name: "port", Pattern: "[0-9]+"}, "", ""},
+		{Variable{Name: "port", Pattern: "[0-9]+", Required: true}, "", "variable port is required"},
+		{Variable{Name: "slug", Pattern: "[a-z]+|[0-9]+"}, "abc1", "does not match"},
+		{Variable{Name: "db", Choices: []string{"none", "postgres"}}, "postgres", ""},
+		{Variable{Name: "db", Choices: []string{"none", "postgres"}}, "mysql", `variable db: "mysql" is not one of none, postgres`},
+		{Variable{Name: "Name"}, "My App", ""},
+		{Variable{Name: "Name"}, "app/../x", "invalid"},
+		{Variable{Name: "Module"}, "example.com/app", ""},
+		{Variable{Name: "Module"}, "example.com/app/", "invalid"},
+	}
+	for _, tt := range tests {
+		err := tt.v.Check(tt.value)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s.Check(%q): %v", tt.v.Name, tt.value, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%s.Check(%q): got error %v, want %q", tt.v.Name, tt.value, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	m := &Manifest{
+		Variables: []Variable{
+			{Name: "port", Pattern: "[0-9"},
+			{Name: "db", Choices: []string{"none", "postgres"}, Default: "mysql"},
+			{Name: "css", Choices: []string{"cdn", "tailwind"}, Default: "{{.Vars.db}}"},
+			{Name: "db"},
+			{},
+		},
+		Directories: []string{"../outside"},
+		Files:       []FileMapping{{Src: "a.tmpl", When: "eq .Vars.db ("}, {Src: "/abs"}},
+		Hooks:       []Hook{{}, {Step: "make-coffee"}, {Step: "git-init", Run: "true"}},
+		Features:    map[string]Feature{"empty": {}},
+	}
+	err := m.Validate()
+	if err == nil {
+		t.Fatal("Validate succeeded")
+	}
+	for _, want := range []string{
+		"name is required",
+		"variable port: error parsing regexp",
+		`variable db: default "mysql" is not one of none, postgres`,
+		"variable db: declared twice",
+		"variable without a name",
+		`directory "../outside": invalid path`,
+		"file a.tmpl: when:",
+		`file "/abs": invalid src`,
+		"hook 1: needs step or run",
+		`hook 2: unknown step "make-coffee"`,
+		"hook 3: step and run are exclusive",
+		"feature empty: no paths",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate: error lacks %q:\n%v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "variable css") {
+		t.Errorf("Validate rejected a templated default:\n%v", err)
+	}
+
+	builtin, err := LoadManifest(Templates())
+	if err != nil {
+		t.Fatalf("the built-in manifest: %v", err)
+	}
+	if err := builtin.Validate(); err != nil {
+		t.Errorf("the built-in manifest: %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package scaffold
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// CheckModule reports whether modulePath is a valid Go module path, e.g.
+// github.com/cagrigit-hub/golosus or go.corp.example/platform/billing.
+func CheckModule(modulePath string) error {
+	if err := module.CheckPath(modulePath); err != nil {
+		// module.CheckPath wants a dot in the first element, but local
+		// modules like "billing" are fine for go mod init.
+		if !strings.Contains(modulePath, "/") && module.CheckImportPath(modulePath) == nil {
+			return nil
+		}
+		return fmt.Errorf("invalid module path %q: %w", modulePath, unwrapModuleError(err))
+	}
+	return nil
+}
+
+func unwrapModuleError(err error) error {
+	if e, ok := err.(*module.InvalidPathError); ok {
+		return e.Err
+	}
+	return err
+}
+
+// ModuleName returns the last element of modulePath without its major
+// version suffix: go.corp.example/platform/billing/v2 gives billing.
+func ModuleName(modulePath string) string {
+	prefix, _, ok := module.SplitPathVersion(modulePath)
+	if !ok {
+		prefix = modulePath
+	}
+	return path.Base(prefix)
+}
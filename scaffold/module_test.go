@@ -0,0 +1,55 @@
+package scaffold
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckModule(t *testing.T) {
+	tests := []struct {
+		path    string
+		wantErr string
+	}{
+		{"my-app", ""},
+		{"billing", ""},
+		{"github.com/cagrigit-hub/golosus", ""},
+		{"go.corp.example/platform/billing", ""},
+		{"go.corp.example/platform/billing/v2", ""},
+		{"gopkg.in/yaml.v3", ""},
+		{"", "invalid module path"},
+		{"my app", `invalid module path "my app"`},
+		{"github.com/me/my app", "invalid char ' '"},
+		{"github.com/me/café", "invalid char 'é'"},
+		{"github.com/me/app/", "trailing slash"},
+		{"github.com//app", "double slash"},
+		{"platform/billing", "missing dot in first path element"},
+		{"github.com/me/app/v1", "invalid version"},
+	}
+	for _, tt := range tests {
+		err := CheckModule(tt.path)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("CheckModule(%q): %v", tt.path, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("CheckModule(%q): got error %v, want %q", tt.path, err, tt.wantErr)
+		}
+	}
+}
+
+func TestModuleName(t *testing.T) {
+	tests := []struct {
+		path, want string
+	}{
+		{"my-app", "my-app"},
+		{"github.com/cagrigit-hub/golosus", "golosus"},
+		{"go.corp.example/platform/billing", "billing"},
+		{"go.corp.example/platform/billing/v2", "billing"},
+		{"gopkg.in/yaml.v3", "yaml"},
+		{"example.com/app/", "app"},
+	}
+	for _, tt := range tests {
+		if got := ModuleName(tt.path); got != tt.want {
+			t.Errorf("ModuleName(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package scaffold
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Names are the spellings of one project name.
+type Names struct {
+	// Dir is the directory the project is generated into and the last
+	// element of the default module path, e.g. my-app.
+	Dir string
+	// NPM is the package.json name, e.g. my-app.
+	NPM string
+	// Title is the human readable name, e.g. My App.
+	Title string
+}
+
+// SplitWords splits name into words at spaces, dashes, underscores, dots and
+// changes to upper case, so "My App", "my_app" and "MyApp" all give My and
+// App. Only ASCII letters and digits make up words; every other character is
+// returned quoted in invalid.
+func SplitWords(name string) (words, invalid []string) {
+	var cur []rune
+	flush := func() {
+		if len(cur) > 0 {
+			words = append(words, string(cur))
+			cur = nil
+		}
+	}
+	runes := []rune(strings.TrimSpace(name))
+	for i, r := range runes {
+		switch {
+		case r == ' ' || r == '-' || r == '_' || r == '.':
+			flush()
+		case r <= unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if unicode.IsUpper(r) && len(cur) > 0 {
+				prev := cur[len(cur)-1]
+				nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+					flush()
+				}
+			}
+			cur = append(cur, r)
+		default:
+			q := fmt.Sprintf("%q", r)
+			if !slices.Contains(invalid, q) {
+				invalid = append(invalid, q)
+			}
+		}
+	}
+	flush()
+	return words, invalid
+}
+
+// NormalizeName derives every spelling of a project name from the words
+// SplitWords finds in name, e.g. my-app and My App for "MyApp". Names with
+// characters SplitWords cannot map are rejected.
+func NormalizeName(name string) (Names, error) {
+	words, invalid := SplitWords(name)
+	if len(invalid) > 0 {
+		return Names{}, fmt.Errorf("invalid project name %q: unsupported characters %s (use letters, digits, spaces, '-', '_' or '.')", name, strings.Join(invalid, ", "))
+	}
+	if len(words) == 0 {
+		return Names{}, fmt.Errorf("invalid project name %q: no letters or digits", name)
+	}
+
+	lower := make([]string, len(words))
+	title := make([]string, len(words))
+	for i, w := range words {
+		lower[i] = strings.ToLower(w)
+		if w == strings.ToUpper(w) && len(w) > 1 {
+			title[i] = w
+		} else {
+			title[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	slug := strings.Join(lower, "-")
+	if len(slug) > 214 {
+		return Names{}, fmt.Errorf("invalid project name %q: longer than 214 characters", name)
+	}
+	return Names{
+		Dir:   slug,
+		NPM:   slug,
+		Title: strings.Join(title, " "),
+	}, nil
+}
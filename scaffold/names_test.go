@@ -0,0 +1,76 @@
+package scaffold
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want Names
+	}{
+		{"my-app", Names{Dir: "my-app", NPM: "my-app", Title: "My App"}},
+		{"My App", Names{Dir: "my-app", NPM: "my-app", Title: "My App"}},
+		{"my_app", Names{Dir: "my-app", NPM: "my-app", Title: "My App"}},
+		{"MyApp", Names{Dir: "my-app", NPM: "my-app", Title: "My App"}},
+		{"  my.app  ", Names{Dir: "my-app", NPM: "my-app", Title: "My App"}},
+		{"My App2", Names{Dir: "my-app2", NPM: "my-app2", Title: "My App2"}},
+		{"HTTPServer", Names{Dir: "http-server", NPM: "http-server", Title: "HTTP Server"}},
+		{"api v2", Names{Dir: "api-v2", NPM: "api-v2", Title: "Api V2"}},
+		{"shop", Names{Dir: "shop", NPM: "shop", Title: "Shop"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeName(tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeName(%q) = %+v, want %+v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		name           string
+		words, invalid []string
+	}{
+		{"MyApp", []string{"My", "App"}, nil},
+		{"HTTPServer", []string{"HTTP", "Server"}, nil},
+		{"user_profile-v2", []string{"user", "profile", "v2"}, nil},
+		{"api V2x", []string{"api", "V2x"}, nil},
+		{"my/app!", []string{"myapp"}, []string{"'/'", "'!'"}},
+		{" . ", nil, nil},
+	}
+	for _, tt := range tests {
+		words, invalid := SplitWords(tt.name)
+		if !slices.Equal(words, tt.words) || !slices.Equal(invalid, tt.invalid) {
+			t.Errorf("SplitWords(%q) = %q, %q; want %q, %q", tt.name, words, invalid, tt.words, tt.invalid)
+		}
+	}
+}
+
+func TestNormalizeNameInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr string
+	}{
+		{"", "no letters or digits"},
+		{" - _ ", "no letters or digits"},
+		{"my/app", `unsupported characters '/'`},
+		{"café!", `unsupported characters 'é', '!'`},
+		{strings.Repeat("a", 215), "longer than 214 characters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NormalizeName(tt.name)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NormalizeName(%q): got error %v, want one containing %q", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
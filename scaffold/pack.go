@@ -0,0 +1,344 @@
+package scaffold
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Pack is a template pack. It is laid out like the built-in templates with a
+// manifest at its root.
+type Pack struct {
+	// Source is the location the pack was requested from.
+	Source string
+	// Version pins the fetched content: the resolved commit for git sources
+	// and the archive sha256 for tarballs. It is empty for local directories.
+	Version  string
+	Dir      string
+	Manifest *Manifest
+}
+
+func (p *Pack) FS() fs.FS {
+	return os.DirFS(p.Dir)
+}
+
+// FetchPack resolves source into a validated template pack. Source is either
+// a local directory, a tarball (.tar, .tar.gz or .tgz, local or over http),
+// or a git repository written as git+<url>[#<ref>].
+//
+// Remote packs are cached below the user cache directory and the version
+// they resolved to is pinned, so later generations from the same source are
+// reproducible and work offline. Set refresh to resolve the source again.
+func FetchPack(ctx context.Context, source string, refresh bool) (*Pack, error) {
+	p := &Pack{Source: source}
+	var err error
+	switch {
+	case strings.HasPrefix(source, "git+"):
+		p.Version, p.Dir, err = fetchGit(ctx, source, refresh)
+	case isArchive(source):
+		p.Version, p.Dir, err = fetchArchive(ctx, source, refresh)
+	default:
+		p.Dir = source
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	p.Manifest, err = LoadManifest(p.FS())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	return p, nil
+}
+
+func isArchive(source string) bool {
+	for _, ext := range []string{".tar", ".tar.gz", ".tgz"} {
+		if strings.HasSuffix(source, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+func packCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "golosus", "packs")
+	return dir, os.MkdirAll(dir, 0o755)
+}
+
+// pinned returns the cached directory source was pinned to, if any.
+func pinned(cache, kind, source string) (version, dir string, ok bool) {
+	pins, err := readPins(cache)
+	if err != nil {
+		return "", "", false
+	}
+	version, ok = pins[source]
+	if !ok {
+		return "", "", false
+	}
+	dir = filepath.Join(cache, kind, version)
+	if _, err := os.Stat(dir); err != nil {
+		return "", "", false
+	}
+	return version, dir, true
+}
+
+func readPins(cache string) (map[string]string, error) {
+	pins := map[string]string{}
+	src, err := os.ReadFile(filepath.Join(cache, "pins.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return pins, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pins, json.Unmarshal(src, &pins)
+}
+
+func pin(cache, source, version string) error {
+	pins, err := readPins(cache)
+	if err != nil {
+		return err
+	}
+	pins[source] = version
+	src, err := json.MarshalIndent(pins, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(cache, ".pins-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(src); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(cache, "pins.json"))
+}
+
+// store moves the staged pack into the cache unless that version is already
+// there and returns its final directory.
+func store(staged, cache, kind, version string) (string, error) {
+	dir := filepath.Join(cache, kind, version)
+	if _, err := os.Stat(dir); err == nil {
+		return dir, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		return "", err
+	}
+	return dir, os.Rename(staged, dir)
+}
+
+func fetchGit(ctx context.Context, source string, refresh bool) (string, string, error) {
+	cache, err := packCacheDir()
+	if err != nil {
+		return "", "", err
+	}
+	url, ref, _ := strings.Cut(strings.TrimPrefix(source, "git+"), "#")
+	// Neither may be read as an option by git.
+	if url == "" || strings.HasPrefix(url, "-") {
+		return "", "", fmt.Errorf("%s: invalid git url %q", source, url)
+	}
+	if strings.HasPrefix(ref, "-") {
+		return "", "", fmt.Errorf("%s: invalid ref %q", source, ref)
+	}
+	if !refresh {
+		if version, dir, ok := pinned(cache, "git", source); ok {
+			return version, dir, nil
+		}
+	}
+
+	tmp, err := os.MkdirTemp(cache, ".git-*")
+	if err != nil {
+		return "", "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	if _, err := git(ctx, "", "clone", "--quiet", "--no-checkout", "--", url, tmp); err != nil {
+		return "", "", err
+	}
+	if ref == "" {
+		ref = "HEAD"
+	}
+	commit, err := git(ctx, tmp, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		commit, err = git(ctx, tmp, "rev-parse", "--verify", "--quiet", "origin/"+ref+"^{commit}")
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("unknown ref %q", ref)
+	}
+	if _, err := git(ctx, tmp, "-c", "advice.detachedHead=false", "checkout", "--quiet", commit); err != nil {
+		return "", "", err
+	}
+	if err := os.RemoveAll(filepath.Join(tmp, ".git")); err != nil {
+		return "", "", err
+	}
+
+	dir, err := store(tmp, cache, "git", commit)
+	if err != nil {
+		return "", "", err
+	}
+	return commit, dir, pin(cache, source, commit)
+}
+
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func fetchArchive(ctx context.Context, source string, refresh bool) (string, string, error) {
+	cache, err := packCacheDir()
+	if err != nil {
+		return "", "", err
+	}
+	remote := strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+	if remote && !refresh {
+		if version, dir, ok := pinned(cache, "tar", source); ok {
+			return version, packRoot(dir), nil
+		}
+	}
+
+	var data []byte
+	if remote {
+		data, err = download(ctx, source)
+	} else {
+		data, err = os.ReadFile(source)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256(data)
+	version := hex.EncodeToString(sum[:])
+
+	dir := filepath.Join(cache, "tar", version)
+	if _, err := os.Stat(dir); err != nil {
+		tmp, err := os.MkdirTemp(cache, ".tar-*")
+		if err != nil {
+			return "", "", err
+		}
+		defer os.RemoveAll(tmp)
+		if err := extract(data, tmp); err != nil {
+			return "", "", err
+		}
+		if dir, err = store(tmp, cache, "tar", version); err != nil {
+			return "", "", err
+		}
+	}
+	if remote {
+		if err := pin(cache, source, version); err != nil {
+			return "", "", err
+		}
+	}
+	return version, packRoot(dir), nil
+}
+
+func download(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// extract unpacks a plain or gzipped tar archive into dir.
+func extract(data []byte, dir string) error {
+	var r io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.FromSlash(hdr.Name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("archive entry %q escapes the pack", hdr.Name)
+		}
+		target := filepath.Join(dir, name)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// packRoot steps into the single top level directory archives are commonly
+// wrapped in when the manifest is not at the root.
+func packRoot(dir string) string {
+	for _, name := range ManifestNames {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return dir
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 || !entries[0].IsDir() {
+		return dir
+	}
+	return filepath.Join(dir, entries[0].Name())
+}
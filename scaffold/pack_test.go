@@ -0,0 +1,217 @@
+package scaffold
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testCache points the pack cache at a fresh directory.
+func testCache(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("LocalAppData", dir)
+}
+
+// gitRepo is a bare repository with a work tree that commits are pushed from.
+type gitRepo struct {
+	t          *testing.T
+	bare, work string
+}
+
+func newGitRepo(t *testing.T) *gitRepo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	r := &gitRepo{t: t, bare: filepath.Join(root, "pack.git"), work: filepath.Join(root, "work")}
+	r.git(root, "init", "--quiet", "--bare", "--initial-branch=main", r.bare)
+	r.git(root, "clone", "--quiet", r.bare, r.work)
+	r.git(r.work, "checkout", "--quiet", "-B", "main")
+	return r
+}
+
+func (r *gitRepo) git(dir string, args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1",
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// commit commits a manifest with the given description and pushes it.
+func (r *gitRepo) commit(description string) string {
+	r.t.Helper()
+	writeFiles(r.t, r.work, map[string]string{"golosus.yaml": "name: test\ndescription: " + description + "\n"})
+	r.git(r.work, "add", "golosus.yaml")
+	r.git(r.work, "commit", "--quiet", "--message", description)
+	r.git(r.work, "push", "--quiet", "origin", "main")
+	return r.git(r.work, "rev-parse", "HEAD")
+}
+
+func TestFetchPackGit(t *testing.T) {
+	testCache(t)
+	repo := newGitRepo(t)
+	first := repo.commit("first")
+	repo.git(repo.work, "tag", "v1")
+	repo.git(repo.work, "push", "--quiet", "origin", "v1")
+	second := repo.commit("second")
+
+	url := "git+file://" + filepath.ToSlash(repo.bare)
+	tests := []struct {
+		source      string
+		wantVersion string
+		wantDesc    string
+	}{
+		{url, second, "second"},
+		{url + "#v1", first, "first"},
+		{url + "#main", second, "second"},
+		{url + "#" + first, first, "first"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			p, err := FetchPack(context.Background(), tt.source, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Version != tt.wantVersion {
+				t.Errorf("Version = %s, want %s", p.Version, tt.wantVersion)
+			}
+			if p.Manifest.Description != tt.wantDesc {
+				t.Errorf("Description = %q, want %q", p.Manifest.Description, tt.wantDesc)
+			}
+		})
+	}
+
+	if _, err := FetchPack(context.Background(), url+"#nope", false); err == nil || !strings.Contains(err.Error(), `unknown ref "nope"`) {
+		t.Errorf("unknown ref: got error %v", err)
+	}
+}
+
+func TestFetchPackGitPin(t *testing.T) {
+	testCache(t)
+	repo := newGitRepo(t)
+	first := repo.commit("first")
+	source := "git+file://" + filepath.ToSlash(repo.bare) + "#main"
+
+	if p, err := FetchPack(context.Background(), source, false); err != nil || p.Version != first {
+		t.Fatalf("first fetch: %v, version %v", err, p)
+	}
+	second := repo.commit("second")
+
+	p, err := FetchPack(context.Background(), source, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Version != first {
+		t.Errorf("pinned fetch resolved %s, want the pinned %s", p.Version, first)
+	}
+
+	p, err = FetchPack(context.Background(), source, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Version != second {
+		t.Errorf("refreshed fetch resolved %s, want %s", p.Version, second)
+	}
+	if p, err = FetchPack(context.Background(), source, false); err != nil || p.Version != second {
+		t.Errorf("fetch after refresh: %v, want the new pin %s", err, second)
+	}
+}
+
+func TestFetchPackGitOptions(t *testing.T) {
+	testCache(t)
+	for _, source := range []string{"git+--upload-pack=touch pwned", "git+-c", "git+", "git+file:///tmp/pack.git#--output=x"} {
+		_, err := FetchPack(context.Background(), source, false)
+		if err == nil || !strings.Contains(err.Error(), "invalid") {
+			t.Errorf("FetchPack(%q): got error %v, want it rejected", source, err)
+		}
+	}
+	if _, err := os.Stat("pwned"); err == nil {
+		t.Errorf("git ran the injected option")
+	}
+}
+
+// tarball returns a gzipped tar archive of files, in order.
+func tarball(t *testing.T, files ...[2]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, f := range files {
+		hdr := &tar.Header{Name: f[0], Mode: 0o644, Size: int64(len(f[1])), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(f[1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestFetchPackArchive(t *testing.T) {
+	testCache(t)
+	archive := filepath.Join(t.TempDir(), "pack.tar.gz")
+	data := tarball(t,
+		[2]string{"pack-1.0/golosus.yaml", "name: test\ndescription: archived\n"},
+		[2]string{"pack-1.0/README.md.tmpl", "# {{.Name}}\n"},
+	)
+	if err := os.WriteFile(archive, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := FetchPack(context.Background(), archive, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Manifest.Description != "archived" {
+		t.Errorf("Description = %q, want %q", p.Manifest.Description, "archived")
+	}
+	if filepath.Base(p.Dir) != "pack-1.0" {
+		t.Errorf("Dir = %s, want the pack-1.0 directory the archive wraps", p.Dir)
+	}
+	if len(p.Version) != 64 {
+		t.Errorf("Version = %q, want a sha256", p.Version)
+	}
+}
+
+func TestExtractRejectsEscapes(t *testing.T) {
+	for _, name := range []string{"../evil.txt", "a/../../evil.txt", "/etc/evil.txt"} {
+		t.Run(name, func(t *testing.T) {
+			parent := t.TempDir()
+			dir := filepath.Join(parent, "pack")
+			data := tarball(t, [2]string{"golosus.yaml", "name: test\n"}, [2]string{name, "evil"})
+			err := extract(data, dir)
+			if err == nil || !strings.Contains(err.Error(), "escapes the pack") {
+				t.Fatalf("extract: got error %v, want an escape error", err)
+			}
+			if _, err := os.Stat(filepath.Join(parent, "evil.txt")); err == nil {
+				t.Errorf("evil.txt was written outside the pack")
+			}
+		})
+	}
+}
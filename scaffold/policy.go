@@ -0,0 +1,65 @@
+package scaffold
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Policy decides what happens when the target directory already has content.
+type Policy int
+
+const (
+	// PolicyFail refuses to generate into a non-empty directory.
+	PolicyFail Policy = iota
+	// PolicyForce overwrites existing files.
+	PolicyForce
+	// PolicySkipExisting keeps every existing file untouched.
+	PolicySkipExisting
+	// PolicyMerge writes only new files and reports existing files whose
+	// content differs from the generated one as conflicts.
+	PolicyMerge
+)
+
+var ErrNotEmpty = errors.New("target directory is not empty")
+
+func (p Policy) String() string {
+	switch p {
+	case PolicyFail:
+		return "fail"
+	case PolicyForce:
+		return "force"
+	case PolicySkipExisting:
+		return "skip-existing"
+	case PolicyMerge:
+		return "merge"
+	}
+	return fmt.Sprintf("Policy(%d)", int(p))
+}
+
+// Report lists what happened to every generated file.
+type Report struct {
+	Written   []string
+	Skipped   []string
+	Unchanged []string
+	Conflicts []string
+}
+
+func isEmptyDir(dir string) (bool, error) {
+	f, err := os.Open(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	if _, err := f.Readdirnames(1); err != nil {
+		if err == io.EOF {
+			return true, nil
+		}
+		return false, err
+	}
+	return false, nil
+}
@@ -0,0 +1,99 @@
+package scaffold
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+type node struct {
+	name     string
+	dir      bool
+	size     int
+	children map[string]*node
+}
+
+func (n *node) child(name string, dir bool) *node {
+	if n.children == nil {
+		n.children = map[string]*node{}
+	}
+	c, ok := n.children[name]
+	if !ok {
+		c = &node{name: name, dir: dir}
+		n.children[name] = c
+	}
+	return c
+}
+
+func (n *node) add(name string, dir bool, size int) {
+	cur := n
+	parts := strings.Split(path.Clean(name), "/")
+	for i, part := range parts {
+		if part == "." {
+			continue
+		}
+		last := i == len(parts)-1
+		cur = cur.child(part, dir || !last)
+		if last && !dir {
+			cur.size = size
+		}
+	}
+}
+
+func (n *node) sorted() []*node {
+	nodes := make([]*node, 0, len(n.children))
+	for _, c := range n.children {
+		nodes = append(nodes, c)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].name < nodes[j].name
+	})
+	return nodes
+}
+
+func (n *node) print(w io.Writer, prefix string) {
+	nodes := n.sorted()
+	for i, c := range nodes {
+		branch, indent := "├── ", "│   "
+		if i == len(nodes)-1 {
+			branch, indent = "└── ", "    "
+		}
+		if c.dir {
+			fmt.Fprintf(w, "%s%s%s/\n", prefix, branch, c.name)
+			c.print(w, prefix+indent)
+			continue
+		}
+		fmt.Fprintf(w, "%s%s%s (%d B)\n", prefix, branch, c.name, c.size)
+	}
+}
+
+// Print writes a tree view of the project with per-file byte sizes to w
+// without touching the disk. When withContent is set the rendered content of
+// every file follows the tree.
+func (p *Project) Print(w io.Writer, withContent bool) {
+	root := &node{dir: true}
+	for _, folder := range p.Folders {
+		root.add(folder, true, 0)
+	}
+	total := 0
+	for _, folder := range p.Folders {
+		for _, file := range p.Files[folder] {
+			root.add(path.Join(folder, file.Name), false, len(file.Content))
+			total += len(file.Content)
+		}
+	}
+
+	fmt.Fprintf(w, "%s/\n", p.Data.Name)
+	root.print(w, "")
+	fmt.Fprintf(w, "\n%d files, %d bytes\n", len(p.Paths()), total)
+
+	if !withContent {
+		return
+	}
+	for _, name := range p.Paths() {
+		content, _ := p.File(name)
+		fmt.Fprintf(w, "\n==> %s <==\n%s\n", path.Join(p.Data.Name, name), content)
+	}
+}
@@ -0,0 +1,165 @@
+// Package scaffold builds Golosus project trees in memory and renders them
+// through a pluggable Writer.
+package scaffold
+
+import (
+	"io/fs"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+)
+
+type File struct {
+	Name    string
+	Content string
+}
+
+// Project is the in-memory tree of a generated project. Folders are relative
+// to the project root and Files are keyed by the folder they live in.
+type Project struct {
+	Data    Data
+	Folders []string
+	Files   map[string][]File
+}
+
+// New renders the built-in templates with data into a project tree. Every
+// layer is rendered the same way on top of them: a file overrides the built-in
+// one with the same output path and any other file is added to the tree.
+func New(data Data, layers ...fs.FS) (*Project, error) {
+	return NewFromPack(Templates(), data, layers...)
+}
+
+// NewFromPack is like New but renders the templates of pack instead of the
+// built-in ones. The pack manifest decides which directories are created,
+// which files are rendered and which variables data has to provide. The
+// resolved variables are recorded in AnswersFile unless the pack renders a
+// file of that name itself.
+func NewFromPack(pack fs.FS, data Data, layers ...fs.FS) (*Project, error) {
+	m, err := LoadManifest(pack)
+	if err != nil {
+		return nil, err
+	}
+	data, err = m.Resolve(data)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered, err := m.Render(pack, data)
+	if err != nil {
+		return nil, err
+	}
+	for _, fsys := range layers {
+		files, err := renderFS(fsys, data)
+		if err != nil {
+			return nil, err
+		}
+		for name, content := range files {
+			rendered[name] = content
+		}
+	}
+	if _, ok := rendered[AnswersFile]; !ok && len(m.Variables) > 0 {
+		if rendered[AnswersFile], err = m.answersFile(data); err != nil {
+			return nil, err
+		}
+	}
+
+	p := &Project{
+		Data:    data,
+		Folders: slices.Clone(m.Directories),
+		Files:   map[string][]File{},
+	}
+	for _, name := range sortedKeys(rendered) {
+		p.Add(name, rendered[name])
+	}
+	return p, nil
+}
+
+// Add puts a file at the slash separated path name, creating its folder.
+func (p *Project) Add(name, content string) {
+	folder, base := path.Split(name)
+	folder = path.Clean(folder)
+	if !slices.Contains(p.Folders, folder) {
+		p.Folders = append(p.Folders, folder)
+	}
+	if p.Files == nil {
+		p.Files = map[string][]File{}
+	}
+	p.Files[folder] = append(p.Files[folder], File{Name: base, Content: content})
+}
+
+// Subset returns a project with only the folders and files at or below one of
+// paths.
+func (p *Project) Subset(paths []string) *Project {
+	within := func(name string) bool {
+		for _, prefix := range paths {
+			prefix = path.Clean(prefix)
+			if prefix == "." || name == prefix || strings.HasPrefix(name, prefix+"/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	sub := &Project{Data: p.Data, Files: map[string][]File{}}
+	for _, folder := range p.Folders {
+		if within(folder) {
+			sub.Folders = append(sub.Folders, folder)
+		}
+		for _, file := range p.Files[folder] {
+			if within(path.Join(folder, file.Name)) {
+				sub.Add(path.Join(folder, file.Name), file.Content)
+			}
+		}
+	}
+	return sub
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Paths returns every file path in the order Render writes them.
+func (p *Project) Paths() []string {
+	var paths []string
+	for _, folder := range p.Folders {
+		for _, file := range p.Files[folder] {
+			paths = append(paths, path.Join(folder, file.Name))
+		}
+	}
+	return paths
+}
+
+// File returns the content of the file at the slash separated path name.
+func (p *Project) File(name string) (string, bool) {
+	folder, base := path.Split(name)
+	folder = path.Clean(folder)
+	for _, file := range p.Files[folder] {
+		if file.Name == base {
+			return file.Content, true
+		}
+	}
+	return "", false
+}
+
+// Render creates every folder and then every file of the project through w.
+func (p *Project) Render(w Writer) error {
+	for _, folder := range p.Folders {
+		if err := w.MkdirAll(folder); err != nil {
+			return err
+		}
+	}
+	for _, folder := range p.Folders {
+		for _, file := range p.Files[folder] {
+			if err := w.WriteFile(path.Join(folder, file.Name), []byte(file.Content)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
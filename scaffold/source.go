@@ -0,0 +1,41 @@
+package scaffold
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// UserTemplatesDir returns the per-user template directory,
+// ~/.config/golosus/templates on Linux.
+func UserTemplatesDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "golosus", "templates"), nil
+}
+
+// TemplateLayers returns the template directories that are laid over the
+// built-in templates: the user template directory when it exists, followed by
+// dir when it is not empty. Later layers win.
+func TemplateLayers(dir string) ([]fs.FS, error) {
+	var layers []fs.FS
+	if user, err := UserTemplatesDir(); err == nil {
+		if info, err := os.Stat(user); err == nil && info.IsDir() {
+			layers = append(layers, os.DirFS(user))
+		}
+	}
+	if dir != "" {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("%s: not a directory", dir)
+		}
+		layers = append(layers, os.DirFS(dir))
+	}
+	return layers, nil
+}
@@ -0,0 +1,116 @@
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	"io/fs"
+	"strings"
+	"text/template"
+)
+
+//go:embed all:templates
+var builtin embed.FS
+
+// TemplateExt marks files that are executed with text/template. The extension
+// is dropped from the generated file name; other files are copied verbatim.
+const TemplateExt = ".tmpl"
+
+// Data holds the variables every template is rendered with.
+type Data struct {
+	// Name is the project name and the directory it is generated into. It is
+	// normalized to lower case words joined by dashes, see NormalizeName.
+	Name string
+	// Title is the human readable project name, e.g. for page titles.
+	Title string
+	// NPMName is the package.json name of the project.
+	NPMName string
+	// Module is the Go module path, e.g. github.com/cagrigit-hub/golosus.
+	Module string
+	// Author is the GitHub user name of the project author.
+	Author string
+	// Vars holds the variables a template pack declares in its manifest.
+	Vars map[string]string
+}
+
+// Get returns the variable name: a field for Name, Module and Author and an
+// entry of Vars otherwise.
+func (d *Data) Get(name string) string {
+	switch name {
+	case "Name":
+		return d.Name
+	case "Module":
+		return d.Module
+	case "Author":
+		return d.Author
+	}
+	return d.Vars[name]
+}
+
+// Set sets the variable name, see Get.
+func (d *Data) Set(name, value string) {
+	switch name {
+	case "Name":
+		d.Name = value
+	case "Module":
+		d.Module = value
+	case "Author":
+		d.Author = value
+	default:
+		if d.Vars == nil {
+			d.Vars = map[string]string{}
+		}
+		d.Vars[name] = value
+	}
+}
+
+// Templates returns the built-in templates. Paths mirror the generated tree.
+func Templates() fs.FS {
+	sub, err := fs.Sub(builtin, "templates")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// renderFS renders every file in fsys with data and returns the contents keyed
+// by their slash separated output path.
+func renderFS(fsys fs.FS, data Data) (map[string]string, error) {
+	out := map[string]string{}
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || isManifest(name) {
+			return err
+		}
+		content, err := renderFile(fsys, name, data)
+		if err != nil {
+			return err
+		}
+		out[strings.TrimSuffix(name, TemplateExt)] = content
+		return nil
+	})
+	return out, err
+}
+
+// renderFile executes name with data when it is a template and returns it
+// verbatim otherwise.
+func renderFile(fsys fs.FS, name string, data Data) (string, error) {
+	src, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasSuffix(name, TemplateExt) {
+		return string(src), nil
+	}
+	return render(name, string(src), data)
+}
+
+func render(name, src string, data Data) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(src)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
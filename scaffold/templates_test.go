@@ -0,0 +1,187 @@
+package scaffold
+
+import (
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestNewOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		vars map[string]string
+		// want maps files to text they contain, absent the files that must
+		// not be generated.
+		want   map[string][]string
+		absent []string
+	}{
+		{
+			name: "echo",
+			vars: map[string]string{},
+			want: map[string][]string{
+				"handler/util.go":         {"func render(c echo.Context, component templ.Component) error"},
+				"cmd/main.go":             {"app := echo.New()", "app.Start("},
+				"go.mod":                  {"github.com/labstack/echo/v4 v4.11.4"},
+				"view/layout/base.templ":  {"https://unpkg.com/htmx.org@1.9.10", "alpinejs@3.x.x", "https://cdn.tailwindcss.com", `src="/static/bundled/bundle.js"`},
+				"typescript/package.json": {`"build": "rimraf ./ts-build && npx tsc && browserify`, `"htmx.org"`, `"alpinejs"`, `"browserify"`},
+			},
+			absent: []string{"view/layout/integrity.go", "view/layout/assets.go", "typescript/build.js", "typescript/styles.css", "typescript/vendor.js"},
+		},
+		{
+			name: "nethttp",
+			vars: map[string]string{"backend": "nethttp"},
+			want: map[string][]string{
+				"handler/util.go": {"func render(w http.ResponseWriter, r *http.Request, component templ.Component)"},
+				"cmd/main.go":     {"http.NewServeMux()", `"GET /example"`},
+				"go.mod":          {"github.com/a-h/templ"},
+			},
+		},
+		{
+			name: "chi",
+			vars: map[string]string{"backend": "chi"},
+			want: map[string][]string{
+				"handler/util.go": {"func render(w http.ResponseWriter, r *http.Request, component templ.Component)"},
+				"cmd/main.go":     {"chi.NewRouter()", `r.Get("/example", exampleHandler.HandleExampleShow)`},
+				"go.mod":          {"github.com/go-chi/chi/v5 v5.0.12"},
+			},
+		},
+		{
+			name: "gin",
+			vars: map[string]string{"backend": "gin"},
+			want: map[string][]string{
+				"handler/util.go": {"func render(c *gin.Context, component templ.Component)"},
+				"cmd/main.go":     {"gin.Default()"},
+				"go.mod":          {"github.com/gin-gonic/gin v1.9.1"},
+			},
+		},
+		{
+			name: "fiber",
+			vars: map[string]string{"backend": "fiber"},
+			want: map[string][]string{
+				"handler/util.go": {"func render(c *fiber.Ctx, component templ.Component) error"},
+				"cmd/main.go":     {"fiber.New()"},
+				"go.mod":          {"github.com/gofiber/fiber/v2 v2.52.0"},
+			},
+		},
+		{
+			name: "pnpm",
+			vars: map[string]string{"packageManager": "pnpm", "css": "tailwind"},
+			want: map[string][]string{
+				"Makefile": {"cd ./typescript && pnpm install", "cd ./typescript && pnpm run build", "cd ./typescript && pnpm run css"},
+			},
+		},
+		{
+			name: "htmx",
+			vars: map[string]string{"frontend": "htmx"},
+			want: map[string][]string{
+				"view/layout/base.templ":  {"htmx.org@1.9.10"},
+				"typescript/package.json": {`"htmx.org"`},
+			},
+		},
+		{
+			name: "htmx-hyperscript",
+			vars: map[string]string{"frontend": "htmx-hyperscript"},
+			want: map[string][]string{
+				"view/layout/base.templ":  {"htmx.org@1.9.10", "hyperscript.org@0.9.12"},
+				"typescript/package.json": {`"hyperscript.org"`},
+			},
+		},
+		{
+			name: "datastar",
+			vars: map[string]string{"frontend": "datastar"},
+			want: map[string][]string{
+				"view/layout/base.templ":  {"datastar@v1.0.0-beta.11/bundles/datastar.js"},
+				"typescript/package.json": {`"@starfederation/datastar"`},
+			},
+		},
+		{
+			name: "none",
+			vars: map[string]string{"frontend": "none", "bundler": "esbuild"},
+			want: map[string][]string{
+				"view/layout/base.templ": {"https://cdn.tailwindcss.com"},
+			},
+		},
+		{
+			name: "css tailwind",
+			vars: map[string]string{"css": "tailwind"},
+			want: map[string][]string{
+				"view/layout/base.templ":        {`<link rel="stylesheet" href="/static/bundled/styles.css"/>`},
+				"typescript/package.json":       {`"css": "tailwindcss -i ./styles.css -o ../assets/bundled/styles.css --minify"`, `"tailwindcss"`},
+				"typescript/styles.css":         {"@tailwind base;"},
+				"typescript/tailwind.config.js": {`content: ["../view/**/*.templ"]`},
+				"Makefile":                      {"npm run css"},
+			},
+		},
+		{
+			name: "assets vendor",
+			vars: map[string]string{"assets": "vendor"},
+			want: map[string][]string{
+				"view/layout/integrity.go": {`const integrityFile = "assets/bundled/integrity.json"`, "func Integrity(name string) string", "!info.ModTime().Equal(integrity.modTime)"},
+				"view/layout/base.templ":   {`integrity={ Integrity("htmx.min.js") }`, `integrity={ Integrity("styles.css") }`, `integrity={ Integrity("bundle.js") }`},
+				"typescript/package.json":  {`"vendor": "node vendor.js"`, `"css": "tailwindcss`},
+				"typescript/vendor.js":     {`["htmx.org/dist/htmx.min.js", "htmx.min.js"]`, `["alpinejs/dist/cdn.min.js", "alpine.min.js"]`},
+				"Makefile":                 {"npm run vendor"},
+			},
+		},
+		{
+			name: "bundler esbuild",
+			vars: map[string]string{"bundler": "esbuild"},
+			want: map[string][]string{
+				"view/layout/assets.go":   {"func Asset(name string) string"},
+				"view/layout/base.templ":  {`src={ "/static/bundled/" + Asset("bundle.js") }`},
+				"typescript/package.json": {`"build": "tsc && node build.js"`, `"watch": "node build.js --watch"`, `"esbuild"`},
+				"typescript/build.js":     {`entryNames: "[name]-[hash]"`},
+			},
+		},
+		{
+			name: "vendor esbuild",
+			vars: map[string]string{"assets": "vendor", "bundler": "esbuild", "frontend": "datastar"},
+			want: map[string][]string{
+				"view/layout/base.templ": {`integrity={ Integrity(Asset("bundle.js")) }`, `integrity={ Integrity("datastar.js") }`},
+				"typescript/vendor.js":   {`["@starfederation/datastar/dist/datastar.js", "datastar.js"]`},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New(Data{Name: "app", Module: "example.com/app", Vars: tt.vars})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, texts := range tt.want {
+				content, ok := p.File(name)
+				if !ok {
+					t.Errorf("%s was not generated", name)
+					continue
+				}
+				for _, text := range texts {
+					if !strings.Contains(content, text) {
+						t.Errorf("%s lacks %s:\n%s", name, text, content)
+					}
+				}
+			}
+			for _, name := range tt.absent {
+				if _, ok := p.File(name); ok {
+					t.Errorf("%s was generated", name)
+				}
+			}
+
+			fset := token.NewFileSet()
+			for _, name := range p.Paths() {
+				content, _ := p.File(name)
+				switch {
+				case strings.HasSuffix(name, ".go"):
+					if _, err := parser.ParseFile(fset, name, content, parser.AllErrors); err != nil {
+						t.Errorf("%s does not parse: %v", name, err)
+					}
+				case name == "typescript/package.json":
+					if !json.Valid([]byte(content)) {
+						t.Errorf("%s is not valid JSON:\n%s", name, content)
+					}
+				}
+			}
+		})
+	}
+}
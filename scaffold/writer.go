@@ -0,0 +1,123 @@
+package scaffold
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Writer receives the folders and files of a rendered project. Names are slash
+// separated and relative to the project root.
+type Writer interface {
+	MkdirAll(dir string) error
+	WriteFile(name string, data []byte) error
+}
+
+// DiskWriter writes the project below Root on the local filesystem. Existing
+// files are handled according to Policy and every decision is recorded in
+// Report.
+//
+// Base is the directory existing files are looked up in. It defaults to Root
+// and only differs from it when rendering into a staging directory.
+type DiskWriter struct {
+	Root   string
+	Base   string
+	Policy Policy
+	Report Report
+}
+
+func (w *DiskWriter) MkdirAll(dir string) error {
+	return os.MkdirAll(filepath.Join(w.Root, filepath.FromSlash(dir)), os.ModePerm)
+}
+
+func (w *DiskWriter) WriteFile(name string, data []byte) error {
+	target := filepath.Join(w.Root, filepath.FromSlash(name))
+	base := w.Base
+	if base == "" {
+		base = w.Root
+	}
+	if w.Policy == PolicyForce {
+		if err := os.WriteFile(target, data, 0o644); err != nil {
+			return err
+		}
+		w.Report.Written = append(w.Report.Written, name)
+		return nil
+	}
+
+	existing, err := os.ReadFile(filepath.Join(base, filepath.FromSlash(name)))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if err := writeNew(target, data); err != nil {
+			return err
+		}
+		w.Report.Written = append(w.Report.Written, name)
+		return nil
+	case err != nil:
+		return err
+	}
+
+	switch w.Policy {
+	case PolicySkipExisting:
+		w.Report.Skipped = append(w.Report.Skipped, name)
+	case PolicyMerge:
+		if bytes.Equal(existing, data) {
+			w.Report.Unchanged = append(w.Report.Unchanged, name)
+		} else {
+			w.Report.Conflicts = append(w.Report.Conflicts, name)
+		}
+	default:
+		return fmt.Errorf("%s: %w", target, os.ErrExist)
+	}
+	return nil
+}
+
+// writeNew creates target and fails if it already exists, so a file that
+// shows up between the existence check and the write is never truncated.
+func writeNew(target string, data []byte) error {
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// MemWriter keeps the rendered project in memory, which is handy for
+// inspecting, diffing and testing generated trees.
+type MemWriter struct {
+	Dirs  map[string]bool
+	Files map[string][]byte
+}
+
+func NewMemWriter() *MemWriter {
+	return &MemWriter{
+		Dirs:  map[string]bool{},
+		Files: map[string][]byte{},
+	}
+}
+
+func (w *MemWriter) MkdirAll(dir string) error {
+	w.Dirs[filepath.ToSlash(filepath.Clean(dir))] = true
+	return nil
+}
+
+func (w *MemWriter) WriteFile(name string, data []byte) error {
+	w.Files[filepath.ToSlash(filepath.Clean(name))] = append([]byte(nil), data...)
+	return nil
+}
+
+// Names returns the written file names in sorted order.
+func (w *MemWriter) Names() []string {
+	names := make([]string, 0, len(w.Files))
+	for name := range w.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
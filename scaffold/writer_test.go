@@ -0,0 +1,126 @@
+package scaffold
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDiskWriterPolicies(t *testing.T) {
+	tests := []struct {
+		policy  Policy
+		wantErr error
+		// want is the content of a.txt and b.txt after writing.
+		want   [2]string
+		report Report
+	}{
+		{
+			policy:  PolicyFail,
+			wantErr: os.ErrExist,
+		},
+		{
+			policy: PolicyForce,
+			want:   [2]string{"new a", "same"},
+			report: Report{Written: []string{"a.txt", "b.txt", "c.txt"}},
+		},
+		{
+			policy: PolicySkipExisting,
+			want:   [2]string{"old a", "same"},
+			report: Report{Written: []string{"c.txt"}, Skipped: []string{"a.txt", "b.txt"}},
+		},
+		{
+			policy: PolicyMerge,
+			want:   [2]string{"old a", "same"},
+			report: Report{Written: []string{"c.txt"}, Unchanged: []string{"b.txt"}, Conflicts: []string{"a.txt"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, map[string]string{"a.txt": "old a", "b.txt": "same"})
+
+			w := &DiskWriter{Root: root, Policy: tt.policy}
+			var err error
+			for _, f := range []struct{ name, content string }{
+				{"a.txt", "new a"},
+				{"b.txt", "same"},
+				{"c.txt", "new c"},
+			} {
+				if err = w.WriteFile(f.name, []byte(f.content)); err != nil {
+					break
+				}
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("WriteFile: got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+
+			for i, name := range []string{"a.txt", "b.txt"} {
+				if got := readFile(t, filepath.Join(root, name)); got != tt.want[i] {
+					t.Errorf("%s = %q, want %q", name, got, tt.want[i])
+				}
+			}
+			if got := readFile(t, filepath.Join(root, "c.txt")); got != "new c" {
+				t.Errorf("c.txt = %q, want %q", got, "new c")
+			}
+			if !reportEqual(w.Report, tt.report) {
+				t.Errorf("report = %+v, want %+v", w.Report, tt.report)
+			}
+		})
+	}
+}
+
+func TestDiskWriterBase(t *testing.T) {
+	base, staging := t.TempDir(), t.TempDir()
+	writeFiles(t, base, map[string]string{"a.txt": "old a"})
+
+	w := &DiskWriter{Root: staging, Base: base, Policy: PolicySkipExisting}
+	if err := w.WriteFile("a.txt", []byte("new a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteFile("b.txt", []byte("new b")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(staging, "a.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("a.txt exists in Base and was staged anyway")
+	}
+	if got := readFile(t, filepath.Join(staging, "b.txt")); got != "new b" {
+		t.Errorf("b.txt = %q, want %q", got, "new b")
+	}
+}
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func reportEqual(a, b Report) bool {
+	return slices.Equal(a.Written, b.Written) &&
+		slices.Equal(a.Skipped, b.Skipped) &&
+		slices.Equal(a.Unchanged, b.Unchanged) &&
+		slices.Equal(a.Conflicts, b.Conflicts)
+}